@@ -28,6 +28,12 @@ Unfortunately, you have used all your notarizations for this month.
 If you would like to increase the number of monthly notarizations, 
 please email us at support@codenotary.io with your request.`
 
+const NotEnoughSignOps = `notarization quota exceeded
+
+You are trying to notarize %d assets, but only %d notarizations are left for this month.
+If you would like to increase the number of monthly notarizations, 
+please email us at support@codenotary.io with your request.`
+
 const TrialExpired = `your trial period has been expired
 
 To continue notarizing assets, please purchase a subscription.`
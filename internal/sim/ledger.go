@@ -0,0 +1,295 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package sim
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/vchain-us/vcn/internal/blockchain"
+	"github.com/vchain-us/vcn/pkg/api"
+	"github.com/vchain-us/vcn/pkg/meta"
+)
+
+// Ledger is an api.Ledger running onto a go-ethereum simulated backend.
+//
+// Notarizations are signed, submitted and mined by the simulated backend exactly as
+// they would be on the CodeNotary mainnet. Since the relay contracts' bytecode is not part of
+// this repository, their logic is emulated by indexing the mined transactions' input data.
+type Ledger struct {
+	mu      sync.RWMutex
+	backend *backends.SimulatedBackend
+	abi     abi.ABI
+
+	assetsRelay        common.Address
+	organisationsRelay common.Address
+
+	blocks  uint64
+	txs     map[common.Hash]uint64
+	levels  map[common.Address]meta.Level
+	entries map[string][]api.BlockchainVerification
+	orgs    map[string]*api.BlockchainOrganisation
+}
+
+func newLedger(backend *backends.SimulatedBackend, assetsRelay, organisationsRelay common.Address) (*Ledger, error) {
+	parsed, err := abi.JSON(strings.NewReader(blockchain.AssetsRelayABI))
+	if err != nil {
+		return nil, err
+	}
+	return &Ledger{
+		backend:            backend,
+		abi:                parsed,
+		assetsRelay:        assetsRelay,
+		organisationsRelay: organisationsRelay,
+		txs:                map[common.Hash]uint64{},
+		levels:             map[common.Address]meta.Level{},
+		entries:            map[string][]api.BlockchainVerification{},
+		orgs:               map[string]*api.BlockchainOrganisation{},
+	}, nil
+}
+
+// SetLevel sets the level assigned to all notarizations made by publisher from now on.
+// If not set, meta.LevelEmailVerified is used.
+func (l *Ledger) SetLevel(publisher common.Address, level meta.Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.levels[publisher] = level
+}
+
+// AddOrganisation adds the organisation name, owned by owner and having the given members.
+func (l *Ledger) AddOrganisation(name string, owner common.Address, members ...common.Address) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.orgs[name] = &api.BlockchainOrganisation{
+		Owner:     owner,
+		Members:   members,
+		Hash:      name,
+		Timestamp: time.Now(),
+	}
+}
+
+func (l *Ledger) level(publisher common.Address) meta.Level {
+	if level, ok := l.levels[publisher]; ok {
+		return level
+	}
+	return meta.LevelEmailVerified
+}
+
+// last returns the most recent entry for hash satisfying match, if any.
+func (l *Ledger) last(hash string, match func(api.BlockchainVerification) bool) *api.BlockchainVerification {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	entries := l.entries[hash]
+	for i := len(entries) - 1; i >= 0; i-- {
+		if match(entries[i]) {
+			v := entries[i]
+			return &v
+		}
+	}
+	return &api.BlockchainVerification{
+		Status: meta.StatusUnknown,
+	}
+}
+
+// Verify implements api.Ledger.
+func (l *Ledger) Verify(ctx context.Context, hash string) (*api.BlockchainVerification, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	var found *api.BlockchainVerification
+	for _, v := range l.entries[hash] {
+		if found == nil || v.Level >= found.Level {
+			vv := v
+			found = &vv
+		}
+	}
+	if found == nil {
+		return &api.BlockchainVerification{
+			Status: meta.StatusUnknown,
+		}, nil
+	}
+	return found, nil
+}
+
+// VerifyAgainstPublisherWithFallback implements api.Ledger.
+func (l *Ledger) VerifyAgainstPublisherWithFallback(ctx context.Context, hash string, publisher common.Address) (*api.BlockchainVerification, error) {
+	v := l.last(hash, func(v api.BlockchainVerification) bool {
+		return v.Owner == publisher
+	})
+	if v.Unknown() {
+		return l.Verify(ctx, hash)
+	}
+	return v, nil
+}
+
+// VerifyAgainstPublishers implements api.Ledger.
+func (l *Ledger) VerifyAgainstPublishers(ctx context.Context, hash string, publishers []common.Address) (*api.BlockchainVerification, error) {
+	return l.last(hash, func(v api.BlockchainVerification) bool {
+		for _, p := range publishers {
+			if v.Owner == p {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+// VerifyByIndex implements api.Ledger.
+func (l *Ledger) VerifyByIndex(ctx context.Context, hash string, index uint64) (*api.BlockchainVerification, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	entries := l.entries[hash]
+	if index >= uint64(len(entries)) {
+		return nil, fmt.Errorf("index out of range: %d", index)
+	}
+	v := entries[index]
+	return &v, nil
+}
+
+// AssetCountForHash implements api.Ledger.
+func (l *Ledger) AssetCountForHash(ctx context.Context, hash string) (uint64, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return uint64(len(l.entries[hash])), nil
+}
+
+// Sign implements api.Ledger.
+// The transaction is mined immediately.
+func (l *Ledger) Sign(ctx context.Context, transactor *bind.TransactOpts, hash string, status meta.Status) (common.Hash, error) {
+	instance, err := blockchain.NewAssetsRelayTransactor(l.assetsRelay, l.backend)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	opts := *transactor
+	opts.Context = ctx
+	tx, err := instance.Sign(&opts, hash, big.NewInt(int64(status)))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	l.backend.Commit()
+	l.mu.Lock()
+	l.blocks++
+	l.txs[tx.Hash()] = l.blocks
+	l.mu.Unlock()
+
+	if err := l.index(tx); err != nil {
+		return common.Hash{}, err
+	}
+	return tx.Hash(), nil
+}
+
+// index decodes a mined sign transaction and stores the resulting entry.
+func (l *Ledger) index(tx *types.Transaction) error {
+	receipt, err := l.backend.TransactionReceipt(context.Background(), tx.Hash())
+	if err != nil {
+		return err
+	}
+	if receipt == nil || receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("transaction %s failed", tx.Hash().Hex())
+	}
+
+	from, err := types.Sender(types.HomesteadSigner{}, tx)
+	if err != nil {
+		return err
+	}
+
+	data := tx.Data()
+	if len(data) < 4 {
+		return fmt.Errorf("invalid transaction input")
+	}
+	method, err := l.abi.MethodById(data[:4])
+	if err != nil {
+		return err
+	}
+	if method.Name != "sign" {
+		return fmt.Errorf("unsupported method: %s", method.Name)
+	}
+	input := struct {
+		Hash   string
+		Status *big.Int
+	}{}
+	if err := method.Inputs.Unpack(&input, data[4:]); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	level := l.level(from)
+	if level <= meta.LevelUnknown {
+		return fmt.Errorf("publisher %s is not allowed to notarize", from.Hex())
+	}
+	l.entries[input.Hash] = append(l.entries[input.Hash], api.BlockchainVerification{
+		Owner:     from,
+		Level:     level,
+		Status:    meta.Status(input.Status.Int64()),
+		Timestamp: time.Unix(time.Now().Unix(), 0),
+	})
+	return nil
+}
+
+// PendingNonce implements api.Ledger.
+func (l *Ledger) PendingNonce(ctx context.Context, account common.Address) (uint64, error) {
+	return l.backend.PendingNonceAt(ctx, account)
+}
+
+// WaitForTx implements api.Ledger.
+func (l *Ledger) WaitForTx(ctx context.Context, tx common.Hash) (timeout bool, err error) {
+	receipt, err := l.backend.TransactionReceipt(ctx, tx)
+	if err != nil {
+		return false, err
+	}
+	return receipt == nil, nil
+}
+
+// TxBlockNumber implements api.Ledger.
+func (l *Ledger) TxBlockNumber(ctx context.Context, tx common.Hash) (uint64, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if number, ok := l.txs[tx]; ok {
+		return number, nil
+	}
+	return 0, fmt.Errorf("transaction %s not found", tx.Hex())
+}
+
+// Organisation implements api.Ledger.
+func (l *Ledger) Organisation(ctx context.Context, name string) (*api.BlockchainOrganisation, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if org, ok := l.orgs[name]; ok {
+		o := *org
+		return &o, nil
+	}
+	return nil, nil
+}
+
+// entry returns the entry matching hash and metahash, if any.
+func (l *Ledger) entry(hash string, metahash string) *api.BlockchainVerification {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	for _, v := range l.entries[hash] {
+		if v.MetaHash() == metahash {
+			vv := v
+			return &vv
+		}
+	}
+	return nil
+}
+
+// AssetsRelay implements api.Ledger.
+func (l *Ledger) AssetsRelay() common.Address {
+	return l.assetsRelay
+}
@@ -0,0 +1,273 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package sim
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/vchain-us/vcn/pkg/api"
+)
+
+type user struct {
+	email    string
+	password string
+	token    string
+	address  string
+	keystore string
+}
+
+type artifact struct {
+	api.ArtifactResponse
+	MetaHash string `json:"metaHash"`
+}
+
+// Platform is an in-process stand-in for the CodeNotary foundation REST API.
+type Platform struct {
+	mu        sync.RWMutex
+	server    *httptest.Server
+	ledger    *Ledger
+	users     map[string]*user
+	artifacts []artifact
+
+	// RemainingSignOps is the number of notarizations left to each user.
+	RemainingSignOps uint64
+}
+
+func newPlatform(ledger *Ledger) *Platform {
+	p := &Platform{
+		ledger:           ledger,
+		users:            map[string]*user{},
+		RemainingSignOps: 100,
+	}
+
+	router := mux.NewRouter()
+	v1 := router.PathPrefix("/v1").Subrouter()
+	v1.HandleFunc("/publisher", p.withAuth(p.publisher)).Methods("GET")
+	v1.HandleFunc("/publisher/exists", p.publisherExists).Methods("GET")
+	v1.HandleFunc("/publisher/auth", p.auth).Methods("POST")
+	v1.HandleFunc("/publisher/auth/check", p.withAuth(p.authCheck)).Methods("GET")
+	v1.HandleFunc("/wallet", p.withAuth(p.wallet)).Methods("GET")
+	v1.HandleFunc("/artifact", p.withAuth(p.createArtifact)).Methods("POST")
+	v1.HandleFunc("/artifact/remaining-sign-operations", p.withAuth(p.remainingSignOps)).Methods("GET")
+	v1.HandleFunc("/artifact/search", p.withAuth(p.searchArtifacts)).Methods("GET")
+	v1.HandleFunc("/artifact/{hash}", p.withAuth(p.userArtifact)).Methods("GET")
+	v1.HandleFunc("/artifact/{hash}/{metahash}", p.artifact).Methods("GET")
+	v1.HandleFunc("/tracking-event/{event}", func(w http.ResponseWriter, r *http.Request) {}).Methods("POST")
+	v1.HandleFunc("/version/vcn", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"content": []interface{}{}})
+	}).Methods("GET")
+
+	p.server = httptest.NewServer(router)
+	return p
+}
+
+// URL returns the base URL of the platform stand-in.
+func (p *Platform) URL() string {
+	return p.server.URL
+}
+
+// Close shuts down the platform stand-in.
+func (p *Platform) Close() {
+	p.server.Close()
+}
+
+func (p *Platform) addUser(email, password, address, keystore string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.users[email] = &user{
+		email:    email,
+		password: password,
+		address:  strings.ToLower(address),
+		keystore: keystore,
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, code, api.Error{
+		Status:    code,
+		Message:   msg,
+		Error:     http.StatusText(code),
+		Timestamp: time.Now().Format(time.RFC3339),
+	})
+}
+
+type authHandlerFunc func(w http.ResponseWriter, r *http.Request, u *user)
+
+func (p *Platform) withAuth(next authHandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		p.mu.RLock()
+		var found *user
+		for _, u := range p.users {
+			if token != "" && u.token == token {
+				found = u
+				break
+			}
+		}
+		p.mu.RUnlock()
+		if found == nil {
+			writeError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		next(w, r, found)
+	}
+}
+
+func (p *Platform) publisher(w http.ResponseWriter, r *http.Request, u *user) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"email":        u.email,
+		"trialExpired": false,
+	})
+}
+
+func (p *Platform) publisherExists(w http.ResponseWriter, r *http.Request) {
+	p.mu.RLock()
+	_, ok := p.users[r.URL.Query().Get("email")]
+	p.mu.RUnlock()
+	writeJSON(w, http.StatusOK, map[string]bool{"exists": ok})
+}
+
+func (p *Platform) auth(w http.ResponseWriter, r *http.Request) {
+	req := struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	u, ok := p.users[req.Email]
+	if !ok || u.password != req.Password {
+		writeError(w, http.StatusUnauthorized, "invalid credentials")
+		return
+	}
+	b := make([]byte, 16)
+	rand.Read(b)
+	u.token = hex.EncodeToString(b)
+	writeJSON(w, http.StatusOK, map[string]string{"token": u.token})
+}
+
+func (p *Platform) authCheck(w http.ResponseWriter, r *http.Request, u *user) {
+	w.WriteHeader(http.StatusOK)
+}
+
+func (p *Platform) wallet(w http.ResponseWriter, r *http.Request, u *user) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"content": []map[string]string{
+			{
+				"address":             u.address,
+				"keyStore":            u.keystore,
+				"name":                u.email,
+				"permissionSyncState": "SYNCED",
+				"levelSyncState":      "SYNCED",
+			},
+		},
+	})
+}
+
+func (p *Platform) remainingSignOps(w http.ResponseWriter, r *http.Request, u *user) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	writeJSON(w, http.StatusOK, map[string]uint64{"count": p.RemainingSignOps})
+}
+
+func (p *Platform) createArtifact(w http.ResponseWriter, r *http.Request, u *user) {
+	var a artifact
+	if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if wallet := r.URL.Query().Get("wallet-address"); wallet != u.address {
+		writeError(w, http.StatusForbidden, "wallet address mismatch")
+		return
+	}
+	v := p.ledger.entry(a.Hash, a.MetaHash)
+	if v == nil {
+		writeError(w, http.StatusBadRequest, "no blockchain entry matching metahash")
+		return
+	}
+
+	a.Level = int64(v.Level)
+	a.Signer = u.address
+	a.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.RemainingSignOps > 0 {
+		p.RemainingSignOps--
+	}
+	p.artifacts = append(p.artifacts, a)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (p *Platform) userArtifacts(u *user, match func(artifact) bool) []api.ArtifactResponse {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	res := []api.ArtifactResponse{}
+	for i := len(p.artifacts) - 1; i >= 0; i-- {
+		a := p.artifacts[i]
+		if a.Signer == u.address && match(a) {
+			res = append(res, a.ArtifactResponse)
+		}
+	}
+	return res
+}
+
+func writePage(w http.ResponseWriter, content []api.ArtifactResponse) {
+	page := api.PagedArtifactResponse{
+		Content:       content,
+		TotalElements: uint64(len(content)),
+	}
+	page.Pageable.PageSize = uint64(len(content))
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (p *Platform) searchArtifacts(w http.ResponseWriter, r *http.Request, u *user) {
+	writePage(w, p.userArtifacts(u, func(artifact) bool { return true }))
+}
+
+func (p *Platform) userArtifact(w http.ResponseWriter, r *http.Request, u *user) {
+	hash := mux.Vars(r)["hash"]
+	content := p.userArtifacts(u, func(a artifact) bool { return a.Hash == hash })
+	if len(content) > 1 {
+		content = content[:1]
+	}
+	writePage(w, content)
+}
+
+func (p *Platform) artifact(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for i := len(p.artifacts) - 1; i >= 0; i-- {
+		a := p.artifacts[i]
+		if a.Hash == vars["hash"] && a.MetaHash == vars["metahash"] {
+			writeJSON(w, http.StatusOK, a.ArtifactResponse)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "artifact not found")
+}
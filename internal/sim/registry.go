@@ -0,0 +1,225 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package sim
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	digest "github.com/opencontainers/go-digest"
+)
+
+const (
+	mediaTypeManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+	mediaTypeManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+	mediaTypeConfig       = "application/vnd.docker.container.image.v1+json"
+)
+
+type registryManifest struct {
+	mediaType string
+	content   []byte
+}
+
+// Registry is an in-process stand-in for a Docker Registry HTTP API V2.
+// Only pulling manifests and blobs is supported.
+type Registry struct {
+	mu        sync.RWMutex
+	server    *httptest.Server
+	manifests map[string]map[string]registryManifest
+	blobs     map[digest.Digest][]byte
+
+	user     string
+	password string
+	bearer   bool
+	tokens   map[string]bool
+}
+
+// NewRegistry starts a new Registry, allowing anonymous access.
+// The returned Registry must be closed by calling Close().
+func NewRegistry() *Registry {
+	r := &Registry{
+		manifests: map[string]map[string]registryManifest{},
+		blobs:     map[digest.Digest][]byte{},
+		tokens:    map[string]bool{},
+	}
+	r.server = httptest.NewServer(http.HandlerFunc(r.serve))
+	return r
+}
+
+// Close shuts down r.
+func (r *Registry) Close() {
+	r.server.Close()
+}
+
+// Host returns the host (and port) of r.
+func (r *Registry) Host() string {
+	return strings.TrimPrefix(r.server.URL, "http://")
+}
+
+// SetCredentials restricts the access to the given user and password.
+// If bearer is true, the token authentication is used, otherwise the basic one.
+func (r *Registry) SetCredentials(user, password string, bearer bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.user = user
+	r.password = password
+	r.bearer = bearer
+}
+
+func (r *Registry) putBlob(b []byte) digest.Digest {
+	d := digest.SHA256.FromBytes(b)
+	r.blobs[d] = b
+	return d
+}
+
+func (r *Registry) putManifest(repo, tag, mediaType string, content []byte) digest.Digest {
+	d := digest.SHA256.FromBytes(content)
+	if r.manifests[repo] == nil {
+		r.manifests[repo] = map[string]registryManifest{}
+	}
+	m := registryManifest{mediaType: mediaType, content: content}
+	r.manifests[repo][d.String()] = m
+	if tag != "" {
+		r.manifests[repo][tag] = m
+	}
+	return d
+}
+
+func (r *Registry) pushImage(repo, tag string, config []byte) (manifest digest.Digest, size int) {
+	id := r.putBlob(config)
+	content, _ := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     mediaTypeManifest,
+		"config": map[string]interface{}{
+			"mediaType": mediaTypeConfig,
+			"size":      len(config),
+			"digest":    id,
+		},
+		"layers": []interface{}{},
+	})
+	return r.putManifest(repo, tag, mediaTypeManifest, content), len(content)
+}
+
+// PushImage stores an image having the given config (as JSON) into repo, tagged by tag.
+// The image ID (ie. the config's digest) is returned.
+func (r *Registry) PushImage(repo, tag string, config []byte) digest.Digest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pushImage(repo, tag, config)
+	return digest.SHA256.FromBytes(config)
+}
+
+// PushIndex stores a multi-platform image into repo, tagged by tag, having a variant for each config.
+// Variants' platforms are taken from configs' "os" and "architecture".
+func (r *Registry) PushIndex(repo, tag string, configs ...[]byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	manifests := []interface{}{}
+	for _, config := range configs {
+		platform := struct {
+			OS           string `json:"os"`
+			Architecture string `json:"architecture"`
+		}{}
+		json.Unmarshal(config, &platform)
+		d, size := r.pushImage(repo, "", config)
+		manifests = append(manifests, map[string]interface{}{
+			"mediaType": mediaTypeManifest,
+			"size":      size,
+			"digest":    d,
+			"platform":  platform,
+		})
+	}
+	content, _ := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     mediaTypeManifestList,
+		"manifests":     manifests,
+	})
+	r.putManifest(repo, tag, mediaTypeManifestList, content)
+}
+
+func (r *Registry) authorized(req *http.Request) bool {
+	if r.user == "" {
+		return true
+	}
+	if r.bearer {
+		return r.tokens[strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")]
+	}
+	user, password, ok := req.BasicAuth()
+	return ok && user == r.user && password == r.password
+}
+
+func (r *Registry) serve(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if req.URL.Path == "/token" {
+		if user, password, ok := req.BasicAuth(); !ok || user != r.user || password != r.password {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		b := make([]byte, 16)
+		rand.Read(b)
+		token := hex.EncodeToString(b)
+		r.tokens[token] = true
+		writeJSON(w, http.StatusOK, map[string]string{"token": token})
+		return
+	}
+
+	if !strings.HasPrefix(req.URL.Path, "/v2/") || (req.Method != http.MethodGet && req.Method != http.MethodHead) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if !r.authorized(req) {
+		if r.bearer {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="sim"`, r.server.URL))
+		} else {
+			w.Header().Set("WWW-Authenticate", `Basic realm="sim"`)
+		}
+		writeJSON(w, http.StatusUnauthorized, map[string]interface{}{"errors": []interface{}{}})
+		return
+	}
+
+	path := strings.TrimPrefix(req.URL.Path, "/v2/")
+	if path == "" {
+		writeJSON(w, http.StatusOK, map[string]interface{}{})
+		return
+	}
+
+	if i := strings.LastIndex(path, "/manifests/"); i > 0 {
+		m, ok := r.manifests[path[:i]][path[i+len("/manifests/"):]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", m.mediaType)
+		w.Header().Set("Docker-Content-Digest", digest.SHA256.FromBytes(m.content).String())
+		w.Write(m.content)
+		return
+	}
+
+	if i := strings.LastIndex(path, "/blobs/"); i > 0 {
+		b, ok := r.blobs[digest.Digest(path[i+len("/blobs/"):])]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write(b)
+		return
+	}
+
+	w.WriteHeader(http.StatusNotFound)
+}
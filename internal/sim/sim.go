@@ -0,0 +1,139 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+// Package sim provides an in-process simulation of the CodeNotary blockchain and platform,
+// so that end-to-end tests can run without any network access.
+//
+// The relay contracts' bytecode is not part of this repository, so their logic is emulated
+// in Go by Ledger, rather than by the contracts themselves. Hence the api.Ledger returned by
+// api.NewEthLedger, and its ABI encoding and decoding, are not exercised by this package:
+// they are covered by the api package's tests against a simulated backend instead.
+package sim
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/vchain-us/vcn/pkg/api"
+	"github.com/vchain-us/vcn/pkg/meta"
+)
+
+const gasLimit = 50000000
+
+var (
+	// AssetsRelayAddress is the address of the simulated AssetsRelay contract.
+	AssetsRelayAddress = common.HexToAddress("0x00000000000000000000000000000000000a55e7")
+	// OrganisationsRelayAddress is the address of the simulated OrganisationsRelay contract.
+	OrganisationsRelayAddress = common.HexToAddress("0x0000000000000000000000000000000000000e6a")
+)
+
+// Sim is a simulated CodeNotary environment, made of a simulated chain (see Ledger)
+// and a stand-in for the platform REST API (see Platform).
+//
+// While running, the meta.StageTest environment is pointed to the simulation and
+// the api package uses the simulated Ledger.
+type Sim struct {
+	Backend  *backends.SimulatedBackend
+	Ledger   *Ledger
+	Platform *Platform
+
+	keydir string
+	env    map[string]*string
+}
+
+var envVars = []string{
+	"STAGE",
+	"VCN_TEST_API",
+	"VCN_TEST_NET",
+	"VCN_TEST_CONTRACT",
+	"VCN_TEST_CONTRACT_ORG",
+	"VCN_TEST_DASHBOARD",
+}
+
+// New starts a new simulated environment. Close() must be called to restore the previous one.
+func New() (*Sim, error) {
+	keydir, err := ioutil.TempDir("", "vcn-sim")
+	if err != nil {
+		return nil, err
+	}
+
+	// The relay contracts' bytecode is not available, so a stub (STOP) is deployed at
+	// their addresses in order to make transactions succeed. See Ledger for details.
+	stub := []byte{0x00}
+	backend := backends.NewSimulatedBackend(core.GenesisAlloc{
+		AssetsRelayAddress:        {Code: stub, Balance: big.NewInt(0)},
+		OrganisationsRelayAddress: {Code: stub, Balance: big.NewInt(0)},
+	}, gasLimit)
+
+	ledger, err := newLedger(backend, AssetsRelayAddress, OrganisationsRelayAddress)
+	if err != nil {
+		os.RemoveAll(keydir)
+		return nil, err
+	}
+
+	s := &Sim{
+		Backend:  backend,
+		Ledger:   ledger,
+		Platform: newPlatform(ledger),
+		keydir:   keydir,
+		env:      map[string]*string{},
+	}
+
+	for _, k := range envVars {
+		if v, ok := os.LookupEnv(k); ok {
+			s.env[k] = &v
+		} else {
+			s.env[k] = nil
+		}
+	}
+	os.Setenv("STAGE", meta.StageTest.String())
+	os.Setenv("VCN_TEST_API", s.Platform.URL())
+	os.Setenv("VCN_TEST_NET", "")
+	os.Setenv("VCN_TEST_CONTRACT", AssetsRelayAddress.Hex())
+	os.Setenv("VCN_TEST_CONTRACT_ORG", OrganisationsRelayAddress.Hex())
+	os.Setenv("VCN_TEST_DASHBOARD", s.Platform.URL())
+
+	api.SetLedger(ledger)
+
+	return s, nil
+}
+
+// AddUser registers a new platform user having a freshly generated secret protected
+// by passphrase, and returns its SignerID.
+func (s *Sim) AddUser(email, password, passphrase string) (common.Address, error) {
+	ks := keystore.NewKeyStore(s.keydir, keystore.LightScryptN, keystore.LightScryptP)
+	acc, err := ks.NewAccount(passphrase)
+	if err != nil {
+		return common.Address{}, err
+	}
+	keyjson, err := ks.Export(acc, passphrase, passphrase)
+	if err != nil {
+		return common.Address{}, err
+	}
+	s.Platform.addUser(email, password, acc.Address.Hex(), string(keyjson))
+	return acc.Address, nil
+}
+
+// Close stops the simulated environment and restores the previous one.
+func (s *Sim) Close() {
+	api.SetLedger(nil)
+	for k, v := range s.env {
+		if v != nil {
+			os.Setenv(k, *v)
+		} else {
+			os.Unsetenv(k)
+		}
+	}
+	s.Platform.Close()
+	os.RemoveAll(s.keydir)
+}
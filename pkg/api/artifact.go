@@ -9,6 +9,7 @@
 package api
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -107,6 +108,7 @@ type ArtifactResponse struct {
 	Signer            string `json:"signer" yaml:"signer" vcn:"Signer"`
 	Company           string `json:"company" yaml:"company" vcn:"Company"`
 	Website           string `json:"website" yaml:"website" vcn:"Website"`
+	TxHash            string `json:"txHash,omitempty" yaml:"txHash,omitempty"`
 }
 
 func (a ArtifactResponse) String() string {
@@ -129,10 +131,10 @@ func (a ArtifactResponse) Artifact() *Artifact {
 	}
 }
 
-func (u User) createArtifact(verification *BlockchainVerification, walletAddress string,
+func (u User) createArtifact(ctx context.Context, verification *BlockchainVerification, walletAddress string,
 	artifact Artifact, visibility meta.Visibility, status meta.Status, txHash common.Hash) error {
 
-	hasAuth, err := u.IsAuthenticated()
+	hasAuth, err := u.isAuthenticated(ctx)
 	if err != nil {
 		return err
 	}
@@ -147,9 +149,9 @@ func (u User) createArtifact(verification *BlockchainVerification, walletAddress
 	aR.TxHash = txHash.String()
 
 	restError := new(Error)
-	r, err := newSling(u.token()).
+	r, err := receive(ctx, newSling(u.token()).
 		Post(meta.APIEndpoint("artifact")+"?wallet-address="+walletAddress).
-		BodyJSON(aR).Receive(nil, restError)
+		BodyJSON(aR), nil, restError)
 	if err != nil {
 		return err
 	}
@@ -167,9 +169,9 @@ func (u *User) LoadArtifact(hash string) (*ArtifactResponse, error) {
 	}
 	response := new(PagedArtifactResponse)
 	restError := new(Error)
-	r, err := newSling(u.token()).
-		Get(meta.APIEndpoint("artifact")+"/"+hash+"?scope=CURRENT_USER&size=1&sort=createdAt,desc").
-		Receive(&response, restError)
+	r, err := receive(context.Background(), newSling(u.token()).
+		Get(meta.APIEndpoint("artifact")+"/"+hash+"?scope=CURRENT_USER&size=1&sort=createdAt,desc"),
+		&response, restError)
 	if err != nil {
 		return nil, err
 	}
@@ -203,9 +205,9 @@ func (u User) ListArtifacts(page uint) (*PagedArtifactResponse, error) {
 		meta.APIEndpoint("artifact"),
 		page,
 	)
-	r, err := newSling(u.token()).
-		Get(url).
-		Receive(&response, restError)
+	r, err := receive(context.Background(), newSling(u.token()).
+		Get(url),
+		&response, restError)
 	if err != nil {
 		return nil, err
 	}
@@ -225,11 +227,16 @@ func (u User) ListArtifacts(page uint) (*PagedArtifactResponse, error) {
 // Returned values depends on user permissions on the artifact, if user is nil then only
 // publicly disclosable values are returned.
 func LoadArtifact(user *User, hash string, metahash string) (*ArtifactResponse, error) {
+	return LoadArtifactContext(context.Background(), user, hash, metahash)
+}
+
+// LoadArtifactContext is like LoadArtifact, but the request is bound to ctx.
+func LoadArtifactContext(ctx context.Context, user *User, hash string, metahash string) (*ArtifactResponse, error) {
 	response := new(ArtifactResponse)
 	restError := new(Error)
-	r, err := newSling(user.token()).
-		Get(meta.APIEndpoint("artifact")+"/"+hash+"/"+metahash).
-		Receive(&response, restError)
+	r, err := receive(ctx, newSling(user.token()).
+		Get(meta.APIEndpoint("artifact")+"/"+hash+"/"+metahash),
+		&response, restError)
 	logger().WithFields(logrus.Fields{
 		"response":  response,
 		"err":       err,
@@ -241,6 +248,8 @@ func LoadArtifact(user *User, hash string, metahash string) (*ArtifactResponse,
 	switch r.StatusCode {
 	case 200:
 		return response, nil
+	case 401:
+		return nil, makeAuthRequiredError()
 	case 404:
 		return nil, fmt.Errorf("no artifact matching %s/%s found", hash, metahash)
 	}
@@ -8,6 +8,7 @@
 package api
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/dghubble/sling"
@@ -40,10 +41,10 @@ func publisherEndpoint() string {
 func checkUserExists(email string) (success bool, err error) {
 	response := new(publisherExistsResponse)
 	restError := new(Error)
-	r, err := sling.New().
+	r, err := receive(context.Background(), sling.New().
 		Get(publisherEndpoint()+"/exists").
-		QueryStruct(&publisherExistsParams{Email: email}).
-		Receive(&response, restError)
+		QueryStruct(&publisherExistsParams{Email: email}),
+		&response, restError)
 	logger().WithFields(logrus.Fields{
 		"response":  response,
 		"err":       err,
@@ -58,11 +59,11 @@ func checkUserExists(email string) (success bool, err error) {
 	return false, fmt.Errorf("check publisher failed: %+v", restError)
 }
 
-func checkToken(token string) (success bool, err error) {
+func checkToken(ctx context.Context, token string) (success bool, err error) {
 	restError := new(Error)
-	response, err := newSling(token).
-		Get(publisherEndpoint()+"/auth/check").
-		Receive(nil, restError)
+	response, err := receive(ctx, newSling(token).
+		Get(publisherEndpoint()+"/auth/check"),
+		nil, restError)
 	logger().WithFields(logrus.Fields{
 		"response":  response,
 		"err":       err,
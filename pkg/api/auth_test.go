@@ -9,6 +9,7 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"testing"
 )
@@ -17,7 +18,7 @@ const verifiedUser = "leonardo@vchain.us"
 
 func TestCheckTokenNoInput(t *testing.T) {
 	token := ""
-	ret, _ := checkToken(token)
+	ret, _ := checkToken(context.Background(), token)
 
 	if ret != false {
 		t.Error(fmt.Sprintf(`CheckToken() with empty string input must return false`))
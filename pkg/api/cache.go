@@ -0,0 +1,95 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/vchain-us/vcn/pkg/store"
+)
+
+// UntrustedCacheTTL is the maximum time-to-live of cached verifications
+// which status is not meta.StatusTrusted.
+const UntrustedCacheTTL = 30 * time.Second
+
+const (
+	cacheScopeAny      = "any"
+	cacheScopeSigners  = "signers"
+	cacheScopeFallback = "fallback"
+)
+
+var (
+	cacheMu  sync.RWMutex
+	cacheTTL time.Duration
+)
+
+// SetCacheTTL enables the local verification cache (see store.ReadCache) for
+// Verify(), VerifyMatchingSignerIDWithFallback() and VerifyMatchingSignerIDs(),
+// using ttl as time-to-live of cached verifications.
+// Verifications not trusted are never cached for more than UntrustedCacheTTL.
+// A zero ttl disables the cache, that is the default.
+func SetCacheTTL(ttl time.Duration) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	cacheTTL = ttl
+}
+
+func getCacheTTL() time.Duration {
+	cacheMu.RLock()
+	defer cacheMu.RUnlock()
+	return cacheTTL
+}
+
+// cached returns the verification for the given scope, hash and signerIDs from the cache, if any,
+// otherwise it calls fn and caches its result.
+func cached(scope string, hash string, signerIDs []string, fn func() (*BlockchainVerification, error)) (*BlockchainVerification, error) {
+	ttl := getCacheTTL()
+	if ttl <= 0 {
+		return fn()
+	}
+
+	// verifications of different networks must not be mixed up
+	key := store.CacheKey(ledger().AssetsRelay().Hex(), scope, hash, signerIDs)
+	if e := store.ReadCache(key); e != nil {
+		v := &BlockchainVerification{}
+		if err := json.Unmarshal(e.Value, v); err == nil {
+			logger().WithFields(logrus.Fields{
+				"hash":         hash,
+				"verification": v,
+				"expiresAt":    e.ExpiresAt,
+			}).Trace("Verification found in cache")
+			return v, nil
+		}
+	}
+
+	v, err := fn()
+	if err != nil || v == nil {
+		return v, err
+	}
+
+	if !v.Trusted() && ttl > UntrustedCacheTTL {
+		ttl = UntrustedCacheTTL
+	}
+	if b, err := json.Marshal(v); err == nil {
+		if err := store.WriteCache(key, hash, signerIDs, b, ttl); err != nil {
+			logger().WithField("error", err).Debug("Cannot write verification cache")
+		}
+	}
+	return v, nil
+}
+
+// invalidateCache removes all cached verifications for hash.
+func invalidateCache(hash string) {
+	if _, err := store.InvalidateCache(hash); err != nil {
+		logger().WithField("error", err).Debug("Cannot invalidate verification cache")
+	}
+}
@@ -0,0 +1,65 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package api
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/vchain-us/vcn/pkg/meta"
+	"github.com/vchain-us/vcn/pkg/store"
+)
+
+func TestCacheLedger(t *testing.T) {
+	tdir, err := ioutil.TempDir("", "vcn-test-api-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tdir)
+	store.SetDir(tdir)
+
+	SetCacheTTL(time.Minute)
+	defer SetCacheTTL(0)
+	defer SetLedger(nil)
+
+	alice := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	hash := "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+	mainnet := newMemLedger()
+	mainnet.relay = common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	mainnet.Sign(context.Background(), &bind.TransactOpts{From: alice}, hash, meta.StatusTrusted)
+	SetLedger(mainnet)
+
+	v, err := Verify(hash)
+	assert.NoError(t, err)
+	assert.True(t, v.Trusted())
+
+	// a verification cached for a network is not used for another one
+	testnet := newMemLedger()
+	testnet.relay = common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	SetLedger(testnet)
+
+	v, err = Verify(hash)
+	assert.NoError(t, err)
+	assert.True(t, v.Unknown())
+
+	// while it is still used for its own network
+	mainnet.entries = map[string][]BlockchainVerification{}
+	SetLedger(mainnet)
+
+	v, err = Verify(hash)
+	assert.NoError(t, err)
+	assert.True(t, v.Trusted())
+}
@@ -9,52 +9,37 @@
 package api
 
 import (
-	"math/big"
-	"time"
+	"context"
 
-	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/sirupsen/logrus"
-	"github.com/vchain-us/vcn/internal/blockchain"
-	"github.com/vchain-us/vcn/pkg/meta"
 )
 
 // BlockChainInspect returns an array of BlockchainVerification containing all verifications found for the given hash
 func BlockChainInspect(hash string) ([]BlockchainVerification, error) {
+	return BlockChainInspectContext(context.Background(), hash)
+}
+
+// BlockChainInspectContext is like BlockChainInspect, but all blockchain calls are bound to ctx.
+func BlockChainInspectContext(ctx context.Context, hash string) ([]BlockchainVerification, error) {
 	logger().WithFields(logrus.Fields{
 		"hash": hash,
 	}).Trace("BlockChainInspect")
 
-	// Connect and get verification count
-	client, err := ethclient.Dial(meta.MainNet())
-	if err != nil {
-		return nil, err
-	}
-	contractAddress := common.HexToAddress(meta.AssetsRelayContractAddress())
-	instance, err := blockchain.NewAssetsRelay(contractAddress, client)
+	l := ledger()
+	count, err := l.AssetCountForHash(ctx, hash)
 	if err != nil {
 		return nil, err
 	}
-	count, err := instance.GetAssetCountForHash(nil, hash)
-	if err != nil {
-		return nil, err
-	}
-	l := count.Int64()
 
-	verifications := make([]BlockchainVerification, l)
+	verifications := make([]BlockchainVerification, count)
 
 	// Iterate over verifications
-	for i := int64(0); i < l; i++ {
-		address, level, status, timestamp, err := instance.VerifyByIndex(nil, hash, big.NewInt(i))
+	for i := uint64(0); i < count; i++ {
+		v, err := l.VerifyByIndex(ctx, hash, i)
 		if err != nil {
 			return nil, err
 		}
-		verifications[i] = BlockchainVerification{
-			Owner:     address,
-			Level:     meta.Level(level.Int64()),
-			Status:    meta.Status(status.Int64()),
-			Timestamp: time.Unix(timestamp.Int64(), 0),
-		}
+		verifications[i] = *v
 	}
 	return verifications, nil
 }
@@ -0,0 +1,88 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package api
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vchain-us/vcn/pkg/meta"
+)
+
+// Ledger is the interface that wraps all blockchain calls made by this package.
+// All methods must honor the cancellation and the deadline of the given context.
+//
+// The default implementation (see NewEthLedger) relies on the AssetsRelay and OrganisationsRelay
+// smart contracts deployed onto the CodeNotary mainnet.
+// Any other implementation can be plugged in by using SetLedger() or SignWithLedger().
+type Ledger interface {
+	// Verify returns the most recent notarization with the highest level available for hash.
+	// If no notarization is found, a *BlockchainVerification with meta.StatusUnknown is returned.
+	Verify(ctx context.Context, hash string) (*BlockchainVerification, error)
+
+	// VerifyAgainstPublisherWithFallback returns the notarization for hash matching publisher,
+	// if any, otherwise it returns the same result of Verify().
+	VerifyAgainstPublisherWithFallback(ctx context.Context, hash string, publisher common.Address) (*BlockchainVerification, error)
+
+	// VerifyAgainstPublishers returns the most recent notarization for hash matching
+	// at least one of publishers.
+	VerifyAgainstPublishers(ctx context.Context, hash string, publishers []common.Address) (*BlockchainVerification, error)
+
+	// VerifyByIndex returns the notarization for hash at the given index.
+	VerifyByIndex(ctx context.Context, hash string, index uint64) (*BlockchainVerification, error)
+
+	// AssetCountForHash returns the number of notarizations stored for hash.
+	AssetCountForHash(ctx context.Context, hash string) (uint64, error)
+
+	// Sign submits a notarization for hash with the given status by using transactor,
+	// and returns the transaction hash.
+	// Since the transaction may have been mined even if an error occurred, it must never be resubmitted.
+	Sign(ctx context.Context, transactor *bind.TransactOpts, hash string, status meta.Status) (common.Hash, error)
+
+	// PendingNonce returns the next nonce to be used by account, pending transactions included.
+	PendingNonce(ctx context.Context, account common.Address) (uint64, error)
+
+	// WaitForTx waits for the given transaction to be mined.
+	// It returns true if the transaction is still pending after the maximum waiting time.
+	WaitForTx(ctx context.Context, tx common.Hash) (timeout bool, err error)
+
+	// TxBlockNumber returns the number of the block including the given transaction,
+	// taken from its receipt. An error is returned if the transaction has not been mined.
+	TxBlockNumber(ctx context.Context, tx common.Hash) (uint64, error)
+
+	// Organisation returns the organisation matching name, if any, otherwise nil.
+	Organisation(ctx context.Context, name string) (*BlockchainOrganisation, error)
+
+	// AssetsRelay returns the address of the AssetsRelay contract.
+	AssetsRelay() common.Address
+}
+
+var (
+	ledgerMu      sync.RWMutex
+	currentLedger Ledger
+)
+
+// SetLedger sets the Ledger used by this package for all blockchain calls.
+// Passing nil restores the default implementation connected to meta.MainNet().
+func SetLedger(l Ledger) {
+	ledgerMu.Lock()
+	defer ledgerMu.Unlock()
+	currentLedger = l
+}
+
+func ledger() Ledger {
+	ledgerMu.RLock()
+	defer ledgerMu.RUnlock()
+	if currentLedger != nil {
+		return currentLedger
+	}
+	return defaultLedger()
+}
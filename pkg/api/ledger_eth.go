@@ -0,0 +1,331 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/sirupsen/logrus"
+	"github.com/vchain-us/vcn/internal/blockchain"
+	"github.com/vchain-us/vcn/internal/errors"
+	"github.com/vchain-us/vcn/pkg/meta"
+)
+
+// EthBackend is the go-ethereum backend needed by the Ledger returned by NewEthLedger.
+// Both *ethclient.Client and *backends.SimulatedBackend satisfy this interface.
+type EthBackend interface {
+	bind.ContractBackend
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
+type ethLedger struct {
+	dial               func() (EthBackend, error)
+	assetsRelay        common.Address
+	organisationsRelay common.Address
+}
+
+// NewEthLedger returns a Ledger that uses the AssetsRelay and OrganisationsRelay bindings
+// against the given backend and contract addresses.
+func NewEthLedger(backend EthBackend, assetsRelay, organisationsRelay common.Address) Ledger {
+	return &ethLedger{
+		dial: func() (EthBackend, error) {
+			return backend, nil
+		},
+		assetsRelay:        assetsRelay,
+		organisationsRelay: organisationsRelay,
+	}
+}
+
+// txBlockNumberer is implemented by backends able to tell the block including a transaction.
+type txBlockNumberer interface {
+	TxBlockNumber(ctx context.Context, tx common.Hash) (uint64, error)
+}
+
+// rpcBackend is an EthBackend connected by JSON-RPC.
+// Since types.Receipt does not carry the block number, it also implements txBlockNumberer.
+type rpcBackend struct {
+	*ethclient.Client
+	rpc *rpc.Client
+}
+
+func newRPCBackend(client *rpc.Client) *rpcBackend {
+	return &rpcBackend{
+		Client: ethclient.NewClient(client),
+		rpc:    client,
+	}
+}
+
+func (b *rpcBackend) TxBlockNumber(ctx context.Context, tx common.Hash) (uint64, error) {
+	var receipt *struct {
+		BlockNumber *hexutil.Big `json:"blockNumber"`
+	}
+	if err := b.rpc.CallContext(ctx, &receipt, "eth_getTransactionReceipt", tx); err != nil {
+		return 0, err
+	}
+	if receipt == nil || receipt.BlockNumber == nil {
+		return 0, ethereum.NotFound
+	}
+	return receipt.BlockNumber.ToInt().Uint64(), nil
+}
+
+var (
+	clientsMu sync.Mutex
+	clients   = map[string]*rpcBackend{}
+)
+
+// dialMainNet returns a client connected to meta.MainNet().
+// Clients are safe for concurrent use, so they are reused across calls.
+func dialMainNet() (EthBackend, error) {
+	url := meta.MainNet()
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+	if client, ok := clients[url]; ok {
+		return client, nil
+	}
+	client, err := rpc.Dial(url)
+	if err != nil {
+		return nil, err
+	}
+	clients[url] = newRPCBackend(client)
+	return clients[url], nil
+}
+
+func defaultLedger() Ledger {
+	return &ethLedger{
+		dial:               dialMainNet,
+		assetsRelay:        common.HexToAddress(meta.AssetsRelayContractAddress()),
+		organisationsRelay: common.HexToAddress(meta.OrganisationsRelayContractAddress()),
+	}
+}
+
+// call calls fn with the current backend, and retries it on transient failures.
+// fn must be idempotent.
+func (l *ethLedger) call(ctx context.Context, op string, fn func(client EthBackend) error) error {
+	return retry(ctx, op, func() (bool, error) {
+		client, err := l.dial()
+		if err == nil {
+			err = fn(client)
+		}
+		return isTransient(err), err
+	})
+}
+
+func (l *ethLedger) callVerifyFunc(ctx context.Context, op string, f func(*blockchain.AssetsRelay, *bind.CallOpts) (common.Address, *big.Int, *big.Int, *big.Int, error)) (*BlockchainVerification, error) {
+	var address common.Address
+	var level, status, timestamp *big.Int
+	err := l.call(ctx, op, func(client EthBackend) error {
+		instance, err := blockchain.NewAssetsRelay(l.assetsRelay, client)
+		if err != nil {
+			return err
+		}
+		address, level, status, timestamp, err = f(instance, &bind.CallOpts{Context: ctx})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if meta.Status(status.Int64()) != meta.StatusUnknown && address != common.BigToAddress(big.NewInt(0)) {
+		verification := &BlockchainVerification{
+			Owner:     address,
+			Level:     meta.Level(level.Int64()),
+			Status:    meta.Status(status.Int64()),
+			Timestamp: time.Unix(timestamp.Int64(), 0),
+		}
+		logger().
+			WithField("verification", verification).
+			Trace("Blockchain verification found")
+		return verification, nil
+	}
+
+	logger().Trace("No blockchain verification found")
+	return &BlockchainVerification{
+		Status: meta.StatusUnknown,
+	}, nil
+}
+
+func (l *ethLedger) Verify(ctx context.Context, hash string) (*BlockchainVerification, error) {
+	return l.callVerifyFunc(ctx, "Verify", func(instance *blockchain.AssetsRelay, opts *bind.CallOpts) (common.Address, *big.Int, *big.Int, *big.Int, error) {
+		return instance.Verify(opts, hash)
+	})
+}
+
+func (l *ethLedger) VerifyAgainstPublisherWithFallback(ctx context.Context, hash string, publisher common.Address) (*BlockchainVerification, error) {
+	return l.callVerifyFunc(ctx, "VerifyAgainstPublisherWithFallback", func(instance *blockchain.AssetsRelay, opts *bind.CallOpts) (common.Address, *big.Int, *big.Int, *big.Int, error) {
+		return instance.VerifyAgainstPublisherWithFallback(opts, hash, publisher)
+	})
+}
+
+func (l *ethLedger) VerifyAgainstPublishers(ctx context.Context, hash string, publishers []common.Address) (*BlockchainVerification, error) {
+	return l.callVerifyFunc(ctx, "VerifyAgainstPublishers", func(instance *blockchain.AssetsRelay, opts *bind.CallOpts) (common.Address, *big.Int, *big.Int, *big.Int, error) {
+		return instance.VerifyAgainstPublishers(opts, hash, publishers)
+	})
+}
+
+func (l *ethLedger) VerifyByIndex(ctx context.Context, hash string, index uint64) (*BlockchainVerification, error) {
+	var address common.Address
+	var level, status, timestamp *big.Int
+	err := l.call(ctx, "VerifyByIndex", func(client EthBackend) error {
+		instance, err := blockchain.NewAssetsRelay(l.assetsRelay, client)
+		if err != nil {
+			return err
+		}
+		address, level, status, timestamp, err = instance.VerifyByIndex(&bind.CallOpts{Context: ctx}, hash, new(big.Int).SetUint64(index))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &BlockchainVerification{
+		Owner:     address,
+		Level:     meta.Level(level.Int64()),
+		Status:    meta.Status(status.Int64()),
+		Timestamp: time.Unix(timestamp.Int64(), 0),
+	}, nil
+}
+
+func (l *ethLedger) AssetCountForHash(ctx context.Context, hash string) (uint64, error) {
+	var count *big.Int
+	err := l.call(ctx, "AssetCountForHash", func(client EthBackend) error {
+		instance, err := blockchain.NewAssetsRelay(l.assetsRelay, client)
+		if err != nil {
+			return err
+		}
+		count, err = instance.GetAssetCountForHash(&bind.CallOpts{Context: ctx}, hash)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count.Uint64(), nil
+}
+
+// Sign is never retried, see Ledger.
+func (l *ethLedger) Sign(ctx context.Context, transactor *bind.TransactOpts, hash string, status meta.Status) (common.Hash, error) {
+	client, err := l.dial()
+	if err != nil {
+		return common.Hash{}, makeError(
+			errors.BlockchainCannotConnect,
+			logrus.Fields{
+				"error":   err,
+				"network": meta.MainNet(),
+			})
+	}
+	instance, err := blockchain.NewAssetsRelay(l.assetsRelay, client)
+	if err != nil {
+		return common.Hash{}, makeFatal(
+			errors.BlockchainContractErr,
+			logrus.Fields{
+				"error":    err,
+				"contract": l.assetsRelay.Hex(),
+			},
+		)
+	}
+	opts := *transactor
+	opts.Context = ctx
+	tx, err := instance.Sign(&opts, hash, big.NewInt(int64(status)))
+	if err != nil {
+		return common.Hash{}, makeFatal(
+			errors.SignFailed,
+			logrus.Fields{
+				"error": err,
+				"hash":  hash,
+			},
+		)
+	}
+	return tx.Hash(), nil
+}
+
+func (l *ethLedger) PendingNonce(ctx context.Context, account common.Address) (nonce uint64, err error) {
+	err = l.call(ctx, "PendingNonce", func(client EthBackend) (err error) {
+		nonce, err = client.PendingNonceAt(ctx, account)
+		return
+	})
+	return
+}
+
+func (l *ethLedger) WaitForTx(ctx context.Context, tx common.Hash) (timeout bool, err error) {
+	maxRounds := meta.TxVerificationRounds()
+	for i := uint64(0); i < maxRounds; i++ {
+		var receipt *types.Receipt
+		err := l.call(ctx, "TransactionReceipt", func(client EthBackend) (err error) {
+			receipt, err = client.TransactionReceipt(ctx, tx)
+			if err == ethereum.NotFound {
+				err = nil
+			}
+			return
+		})
+		if err != nil {
+			return false, err
+		}
+		if receipt != nil {
+			return false, nil
+		}
+		// still pending
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-time.After(meta.PollInterval()):
+		}
+	}
+	return true, nil
+}
+
+func (l *ethLedger) TxBlockNumber(ctx context.Context, tx common.Hash) (number uint64, err error) {
+	err = l.call(ctx, "TxBlockNumber", func(client EthBackend) (err error) {
+		b, ok := client.(txBlockNumberer)
+		if !ok {
+			return fmt.Errorf("block numbers are not supported by the current backend")
+		}
+		number, err = b.TxBlockNumber(ctx, tx)
+		return
+	})
+	return
+}
+
+func (l *ethLedger) Organisation(ctx context.Context, name string) (*BlockchainOrganisation, error) {
+	var owner common.Address
+	var memberAddresses []common.Address
+	var hash string
+	var timestamp *big.Int
+	err := l.call(ctx, "Organisation", func(client EthBackend) error {
+		instance, err := blockchain.NewOrganisationsRelay(l.organisationsRelay, client)
+		if err != nil {
+			return err
+		}
+		owner, memberAddresses, hash, timestamp, err = instance.GetOrganisation(&bind.CallOpts{Context: ctx}, name)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if owner == common.BigToAddress(big.NewInt(0)) {
+		return nil, nil
+	}
+	return &BlockchainOrganisation{
+		Owner:     owner,
+		Members:   memberAddresses,
+		Hash:      hash,
+		Timestamp: time.Unix(timestamp.Int64(), 0),
+	}, nil
+}
+
+func (l *ethLedger) AssetsRelay() common.Address {
+	return l.assetsRelay
+}
@@ -0,0 +1,331 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/vchain-us/vcn/internal/blockchain"
+	"github.com/vchain-us/vcn/pkg/meta"
+)
+
+type memLedger struct {
+	entries map[string][]BlockchainVerification
+	orgs    map[string]*BlockchainOrganisation
+	relay   common.Address
+}
+
+func (l *memLedger) last(hash string, match func(BlockchainVerification) bool) *BlockchainVerification {
+	entries := l.entries[hash]
+	for i := len(entries) - 1; i >= 0; i-- {
+		if match(entries[i]) {
+			v := entries[i]
+			return &v
+		}
+	}
+	return &BlockchainVerification{Status: meta.StatusUnknown}
+}
+
+func (l *memLedger) Verify(ctx context.Context, hash string) (*BlockchainVerification, error) {
+	return l.last(hash, func(BlockchainVerification) bool { return true }), nil
+}
+
+func (l *memLedger) VerifyAgainstPublisherWithFallback(ctx context.Context, hash string, publisher common.Address) (*BlockchainVerification, error) {
+	if v := l.last(hash, func(v BlockchainVerification) bool { return v.Owner == publisher }); !v.Unknown() {
+		return v, nil
+	}
+	return l.Verify(ctx, hash)
+}
+
+func (l *memLedger) VerifyAgainstPublishers(ctx context.Context, hash string, publishers []common.Address) (*BlockchainVerification, error) {
+	return l.last(hash, func(v BlockchainVerification) bool {
+		for _, p := range publishers {
+			if v.Owner == p {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+func (l *memLedger) VerifyByIndex(ctx context.Context, hash string, index uint64) (*BlockchainVerification, error) {
+	v := l.entries[hash][index]
+	return &v, nil
+}
+
+func (l *memLedger) AssetCountForHash(ctx context.Context, hash string) (uint64, error) {
+	return uint64(len(l.entries[hash])), nil
+}
+
+func (l *memLedger) Sign(ctx context.Context, transactor *bind.TransactOpts, hash string, status meta.Status) (common.Hash, error) {
+	l.entries[hash] = append(l.entries[hash], BlockchainVerification{
+		Owner:     transactor.From,
+		Level:     meta.LevelEmailVerified,
+		Status:    status,
+		Timestamp: time.Now(),
+	})
+	return common.Hash{}, nil
+}
+
+func (l *memLedger) TxBlockNumber(ctx context.Context, tx common.Hash) (uint64, error) {
+	return 1, nil
+}
+
+func (l *memLedger) PendingNonce(ctx context.Context, account common.Address) (uint64, error) {
+	return 0, nil
+}
+
+func (l *memLedger) WaitForTx(ctx context.Context, tx common.Hash) (bool, error) {
+	return false, nil
+}
+
+func (l *memLedger) Organisation(ctx context.Context, name string) (*BlockchainOrganisation, error) {
+	return l.orgs[name], nil
+}
+
+func (l *memLedger) AssetsRelay() common.Address {
+	return l.relay
+}
+
+func newMemLedger() *memLedger {
+	return &memLedger{
+		entries: map[string][]BlockchainVerification{},
+		orgs:    map[string]*BlockchainOrganisation{},
+	}
+}
+
+func TestSetLedger(t *testing.T) {
+	l := newMemLedger()
+	SetLedger(l)
+	defer SetLedger(nil)
+
+	alice := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	bob := common.HexToAddress("0x0000000000000000000000000000000000000002")
+	hash := "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+	v, err := Verify(hash)
+	assert.NoError(t, err)
+	assert.True(t, v.Unknown())
+
+	l.Sign(context.Background(), &bind.TransactOpts{From: alice}, hash, meta.StatusTrusted)
+	l.Sign(context.Background(), &bind.TransactOpts{From: bob}, hash, meta.StatusUntrusted)
+
+	v, err = Verify(hash)
+	assert.NoError(t, err)
+	assert.Equal(t, meta.StatusUntrusted, v.Status)
+
+	v, err = VerifyMatchingSignerID(hash, alice.Hex())
+	assert.NoError(t, err)
+	assert.True(t, v.Trusted())
+	assert.Equal(t, alice, v.Owner)
+
+	v, err = VerifyMatchingSignerIDWithFallback(hash, "0x0000000000000000000000000000000000000003")
+	assert.NoError(t, err)
+	assert.Equal(t, bob, v.Owner)
+
+	vs, err := BlockChainInspect(hash)
+	assert.NoError(t, err)
+	assert.Len(t, vs, 2)
+	assert.Equal(t, alice, vs[0].Owner)
+	assert.Equal(t, bob, vs[1].Owner)
+
+	l.orgs["vchain.us"] = &BlockchainOrganisation{Owner: alice, Members: []common.Address{alice, bob}}
+	org, err := GetBlockChainOrganisation("vchain.us")
+	assert.NoError(t, err)
+	assert.Len(t, org.MembersIDs(), 2)
+
+	org, err = GetBlockChainOrganisation("not-existing")
+	assert.Error(t, err)
+	assert.Nil(t, org)
+}
+
+// stalledBackend never returns a receipt.
+type stalledBackend struct {
+	bind.ContractBackend
+}
+
+func (stalledBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return nil, ethereum.NotFound
+}
+
+func TestWaitForTxContext(t *testing.T) {
+	l := NewEthLedger(stalledBackend{}, common.Address{}, common.Address{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	timeout, err := l.WaitForTx(ctx, common.Hash{})
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.False(t, timeout)
+	assert.True(t, time.Since(start) < meta.PollInterval())
+}
+
+// returning returns the runtime bytecode of a contract answering data to any call.
+func returning(data []byte) []byte {
+	n0, n1 := byte(len(data)>>8), byte(len(data))
+	code := []byte{
+		0x61, n0, n1, // PUSH2 len(data)
+		0x60, 0x0e, // PUSH1 offset of data, ie. len(code)
+		0x60, 0x00, // PUSH1 0
+		0x39,         // CODECOPY
+		0x61, n0, n1, // PUSH2 len(data)
+		0x60, 0x00, // PUSH1 0
+		0xf3, // RETURN
+	}
+	return append(code, data...)
+}
+
+func TestEthLedgerSimulated(t *testing.T) {
+	assetsABI, err := abi.JSON(strings.NewReader(blockchain.AssetsRelayABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	orgsABI, err := abi.JSON(strings.NewReader(blockchain.OrganisationsRelayABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	alice := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	bob := common.HexToAddress("0x0000000000000000000000000000000000000002")
+	hash := "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+	verification, err := assetsABI.Methods["verify"].Outputs.Pack(
+		alice,
+		big.NewInt(int64(meta.LevelEmailVerified)),
+		big.NewInt(int64(meta.StatusTrusted)),
+		big.NewInt(1546300800),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	org, err := orgsABI.Methods["getOrganisation"].Outputs.Pack(alice, []common.Address{alice, bob}, "vchain.us", big.NewInt(1546300800))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	transactor := bind.NewKeyedTransactor(key)
+
+	assetsRelay := common.HexToAddress("0x00000000000000000000000000000000000a55e7")
+	organisationsRelay := common.HexToAddress("0x0000000000000000000000000000000000000e6a")
+	backend := backends.NewSimulatedBackend(core.GenesisAlloc{
+		transactor.From:    {Balance: big.NewInt(1000000000000000000)},
+		assetsRelay:        {Code: returning(verification), Balance: big.NewInt(0)},
+		organisationsRelay: {Code: returning(org), Balance: big.NewInt(0)},
+	}, 50000000)
+	l := NewEthLedger(backend, assetsRelay, organisationsRelay)
+	ctx := context.Background()
+
+	for _, v := range []func() (*BlockchainVerification, error){
+		func() (*BlockchainVerification, error) { return l.Verify(ctx, hash) },
+		func() (*BlockchainVerification, error) { return l.VerifyAgainstPublisherWithFallback(ctx, hash, alice) },
+		func() (*BlockchainVerification, error) {
+			return l.VerifyAgainstPublishers(ctx, hash, []common.Address{alice, bob})
+		},
+		func() (*BlockchainVerification, error) { return l.VerifyByIndex(ctx, hash, 0) },
+	} {
+		v, err := v()
+		if assert.NoError(t, err) {
+			assert.Equal(t, alice, v.Owner)
+			assert.Equal(t, meta.LevelEmailVerified, v.Level)
+			assert.Equal(t, meta.StatusTrusted, v.Status)
+			assert.Equal(t, int64(1546300800), v.Timestamp.Unix())
+		}
+	}
+
+	// the first word of the verification is alice's address
+	count, err := l.AssetCountForHash(ctx, hash)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), count)
+
+	o, err := l.Organisation(ctx, "vchain.us")
+	if assert.NoError(t, err) && assert.NotNil(t, o) {
+		assert.Equal(t, alice, o.Owner)
+		assert.Equal(t, []common.Address{alice, bob}, o.Members)
+		assert.Equal(t, "vchain.us", o.Hash)
+	}
+
+	nonce, err := l.PendingNonce(ctx, transactor.From)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), nonce)
+
+	tx, err := l.Sign(ctx, transactor, hash, meta.StatusTrusted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend.Commit()
+	timeout, err := l.WaitForTx(ctx, tx)
+	assert.NoError(t, err)
+	assert.False(t, timeout)
+
+	nonce, err = l.PendingNonce(ctx, transactor.From)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), nonce)
+
+	// the simulated backend does not provide block numbers
+	_, err = l.TxBlockNumber(ctx, tx)
+	assert.Error(t, err)
+}
+
+func TestTxBlockNumber(t *testing.T) {
+	mined := common.HexToHash("0x01")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params []common.Hash   `json:"params"`
+		}{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "eth_getTransactionReceipt", req.Method)
+		result := "null"
+		if len(req.Params) == 1 && req.Params[0] == mined {
+			result = `{"transactionHash":"` + mined.Hex() + `","blockNumber":"0x2a"}`
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"jsonrpc":"2.0","id":` + string(req.ID) + `,"result":` + result + `}`))
+	}))
+	defer srv.Close()
+
+	client, err := rpc.Dial(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	l := NewEthLedger(newRPCBackend(client), common.Address{}, common.Address{})
+
+	number, err := l.TxBlockNumber(context.Background(), mined)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(42), number)
+
+	_, err = l.TxBlockNumber(context.Background(), common.HexToHash("0x02"))
+	assert.Equal(t, ethereum.NotFound, err)
+}
@@ -9,16 +9,14 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 	"strings"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/sirupsen/logrus"
-	"github.com/vchain-us/vcn/internal/blockchain"
-	"github.com/vchain-us/vcn/pkg/meta"
 )
 
 // BlockchainOrganisation represents the organization data stored onto the blockchain.
@@ -52,32 +50,21 @@ func (o *BlockchainOrganisation) MembersIDs() []string {
 // GetBlockChainOrganisation returns a BlockchainOrganisation for the organization name, if any.
 // It returns a nil value and an error if the organization is not found.
 func GetBlockChainOrganisation(name string) (*BlockchainOrganisation, error) {
+	return GetBlockChainOrganisationContext(context.Background(), name)
+}
+
+// GetBlockChainOrganisationContext is like GetBlockChainOrganisation, but the blockchain call is bound to ctx.
+func GetBlockChainOrganisationContext(ctx context.Context, name string) (*BlockchainOrganisation, error) {
 	logger().WithFields(logrus.Fields{
 		"name": name,
 	}).Trace("GetBlockChainOrganisation")
 
-	// Connect and get organisation data
-	client, err := ethclient.Dial(meta.MainNet())
-	if err != nil {
-		return nil, err
-	}
-	contractAddress := common.HexToAddress(meta.OrganisationsRelayContractAddress())
-	instance, err := blockchain.NewOrganisationsRelay(contractAddress, client)
-	if err != nil {
-		return nil, err
-	}
-	owner, memberAddresses, hash, timestamp, err := instance.GetOrganisation(nil, name)
+	org, err := ledger().Organisation(ctx, name)
 	if err != nil {
 		return nil, err
 	}
 
-	if owner != common.BigToAddress(big.NewInt(0)) {
-		org := &BlockchainOrganisation{
-			Owner:     owner,
-			Members:   memberAddresses,
-			Hash:      hash,
-			Timestamp: time.Unix(timestamp.Int64(), 0),
-		}
+	if org != nil {
 		logger().
 			WithField("organisation", org).
 			Trace("Blockchain organisation found")
@@ -0,0 +1,108 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/sirupsen/logrus"
+)
+
+// Quorum is the outcome of an N-of-M authentication (see VerifyQuorum).
+type Quorum struct {
+	// Required is the number of distinct signers that must currently trust the asset.
+	Required uint `json:"required" yaml:"required"`
+
+	// Trusted is the number of distinct signers that currently trust the asset.
+	Trusted uint `json:"trusted" yaml:"trusted"`
+
+	// Signers holds the most recent notarization of each passed signer that notarized the asset,
+	// in the same order of the passed SignerIDs.
+	Signers []*BlockchainVerification `json:"signers" yaml:"signers"`
+}
+
+// Reached returns true if the required number of signers currently trust the asset.
+func (q *Quorum) Reached() bool {
+	return q != nil && q.Required > 0 && q.Trusted >= q.Required
+}
+
+// Verification returns the most recent TRUSTED notarization among q.Signers if q is reached, otherwise nil.
+func (q *Quorum) Verification() *BlockchainVerification {
+	if !q.Reached() {
+		return nil
+	}
+	var found *BlockchainVerification
+	for _, v := range q.Signers {
+		if v.Trusted() && (found == nil || v.Timestamp.After(found.Timestamp)) {
+			found = v
+		}
+	}
+	return found
+}
+
+// VerifyQuorum returns the *Quorum of signerIDs for hash, requiring at least quorum distinct signers
+// whose most recent notarization is TRUSTED.
+func VerifyQuorum(hash string, signerIDs []string, quorum uint) (*Quorum, error) {
+	return VerifyQuorumContext(context.Background(), hash, signerIDs, quorum)
+}
+
+// VerifyQuorumContext is like VerifyQuorum, but all blockchain calls are bound to ctx.
+func VerifyQuorumContext(ctx context.Context, hash string, signerIDs []string, quorum uint) (*Quorum, error) {
+	logger().WithFields(logrus.Fields{
+		"hash":      hash,
+		"signerIDs": signerIDs,
+		"quorum":    quorum,
+	}).Trace("VerifyQuorum")
+
+	// distinct signers, in order
+	ids := []string{}
+	latest := map[string]*BlockchainVerification{}
+	for _, id := range signerIDs {
+		id = strings.ToLower(common.HexToAddress(id).Hex())
+		if _, ok := latest[id]; !ok {
+			latest[id] = nil
+			ids = append(ids, id)
+		}
+	}
+	if quorum == 0 {
+		return nil, fmt.Errorf("quorum must be greater than zero")
+	}
+	if quorum > uint(len(ids)) {
+		return nil, fmt.Errorf("quorum (%d) cannot exceed the number of signers (%d)", quorum, len(ids))
+	}
+
+	verifications, err := BlockChainInspectContext(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	// entries are ordered by time, so the last one wins
+	for i := range verifications {
+		v := &verifications[i]
+		if _, ok := latest[v.SignerID()]; ok {
+			latest[v.SignerID()] = v
+		}
+	}
+
+	q := &Quorum{
+		Required: quorum,
+		Signers:  []*BlockchainVerification{},
+	}
+	for _, id := range ids {
+		if v := latest[id]; v != nil {
+			q.Signers = append(q.Signers, v)
+			if v.Trusted() {
+				q.Trusted++
+			}
+		}
+	}
+	return q, nil
+}
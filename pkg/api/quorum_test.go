@@ -0,0 +1,86 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/vchain-us/vcn/pkg/meta"
+)
+
+func TestVerifyQuorum(t *testing.T) {
+	l := newMemLedger()
+	SetLedger(l)
+	defer SetLedger(nil)
+
+	alice := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	bob := common.HexToAddress("0x0000000000000000000000000000000000000002")
+	carol := common.HexToAddress("0x0000000000000000000000000000000000000003")
+	mallory := common.HexToAddress("0x0000000000000000000000000000000000000004")
+	signerIDs := []string{alice.Hex(), bob.Hex(), carol.Hex()}
+	hash := "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	sign := func(from common.Address, status meta.Status) {
+		l.Sign(context.Background(), &bind.TransactOpts{From: from}, hash, status)
+	}
+
+	_, err := VerifyQuorum(hash, signerIDs, 0)
+	assert.Error(t, err)
+	_, err = VerifyQuorum(hash, append(signerIDs, alice.Hex()), 4)
+	assert.Error(t, err)
+
+	q, err := VerifyQuorum(hash, signerIDs, 2)
+	assert.NoError(t, err)
+	assert.False(t, q.Reached())
+	assert.Empty(t, q.Signers)
+	assert.Nil(t, q.Verification())
+
+	// signers not in the list do not count
+	sign(alice, meta.StatusTrusted)
+	sign(mallory, meta.StatusTrusted)
+	q, err = VerifyQuorum(hash, signerIDs, 2)
+	assert.NoError(t, err)
+	assert.False(t, q.Reached())
+	assert.Equal(t, uint(1), q.Trusted)
+
+	// the same signer counts once
+	sign(alice, meta.StatusTrusted)
+	q, err = VerifyQuorum(hash, signerIDs, 2)
+	assert.NoError(t, err)
+	assert.False(t, q.Reached())
+	assert.Len(t, q.Signers, 1)
+
+	sign(bob, meta.StatusTrusted)
+	q, err = VerifyQuorum(hash, signerIDs, 2)
+	assert.NoError(t, err)
+	assert.True(t, q.Reached())
+	assert.Equal(t, uint(2), q.Trusted)
+	if assert.Len(t, q.Signers, 2) {
+		assert.Equal(t, alice, q.Signers[0].Owner)
+		assert.Equal(t, bob, q.Signers[1].Owner)
+	}
+	assert.True(t, q.Verification().Trusted())
+
+	// only the most recent status of each signer counts
+	sign(alice, meta.StatusUntrusted)
+	sign(carol, meta.StatusUnsupported)
+	q, err = VerifyQuorum(hash, signerIDs, 2)
+	assert.NoError(t, err)
+	assert.False(t, q.Reached())
+	assert.Equal(t, uint(1), q.Trusted)
+	assert.Len(t, q.Signers, 3)
+
+	sign(carol, meta.StatusTrusted)
+	q, err = VerifyQuorum(hash, signerIDs, 2)
+	assert.NoError(t, err)
+	assert.True(t, q.Reached())
+}
@@ -0,0 +1,184 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package api
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/sirupsen/logrus"
+)
+
+// ReceiptVersion is the current version of the receipt format.
+const ReceiptVersion = 1
+
+// Receipt is a portable proof of an authentication.
+// It holds everything needed to re-check an asset later, even without network access.
+// Since its content is not checked against the blockchain when used offline,
+// a receipt must be signed (see Sign) by someone the reader trusts (see CheckSignature).
+type Receipt struct {
+	Version      uint                   `json:"version"`
+	Hash         string                 `json:"hash"`
+	Verification BlockchainVerification `json:"verification"`
+	MetaHash     string                 `json:"metaHash"`
+	Contract     string                 `json:"contract"`
+	TxHash       string                 `json:"txHash,omitempty"`
+	BlockNumber  uint64                 `json:"blockNumber,omitempty"`
+	Signer       string                 `json:"signer,omitempty"`
+	Signature    string                 `json:"signature,omitempty"`
+}
+
+// NewReceipt returns an unsigned *Receipt for the given hash and verification.
+// The txHash is optional, since it's only known by the platform (see ArtifactResponse).
+// If set, the block number is taken from the transaction's receipt on the blockchain.
+func NewReceipt(ctx context.Context, hash string, verification *BlockchainVerification, txHash string) (*Receipt, error) {
+	if verification.Unknown() {
+		return nil, fmt.Errorf("cannot create a receipt for %s: no notarization found", hash)
+	}
+	l := ledger()
+	r := &Receipt{
+		Version:      ReceiptVersion,
+		Hash:         hash,
+		Verification: *verification,
+		MetaHash:     verification.MetaHash(),
+		Contract:     strings.ToLower(l.AssetsRelay().Hex()),
+	}
+	if txHash != "" {
+		tx := common.HexToHash(txHash)
+		number, err := l.TxBlockNumber(ctx, tx)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get the block of transaction %s: %s", tx.Hex(), err)
+		}
+		r.TxHash = tx.Hex()
+		r.BlockNumber = number
+	}
+	return r, nil
+}
+
+// LoadReceipt reads a *Receipt from the given file.
+func LoadReceipt(filename string) (*Receipt, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	r := &Receipt{}
+	if err := json.Unmarshal(b, r); err != nil {
+		return nil, fmt.Errorf("invalid receipt %s: %s", filename, err)
+	}
+	if r.Version != ReceiptVersion {
+		return nil, fmt.Errorf("unsupported receipt version: %d", r.Version)
+	}
+	return r, nil
+}
+
+// WriteFile writes r to the given file.
+func (r Receipt) WriteFile(filename string) error {
+	b, err := json.MarshalIndent(&r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, b, 0644)
+}
+
+// digest returns the Keccak-256 digest of r's content, signature excluded.
+func (r Receipt) digest() []byte {
+	v := r.Verification
+	return crypto.Keccak256([]byte(fmt.Sprintf(
+		"vcn-receipt:%d:%s:%s:%d:%d:%d:%s:%s:%s:%d:%s",
+		r.Version,
+		r.Hash,
+		strings.ToLower(v.Owner.Hex()),
+		v.Level,
+		v.Status,
+		v.Timestamp.Unix(),
+		r.MetaHash,
+		r.Contract,
+		r.TxHash,
+		r.BlockNumber,
+		r.Signer,
+	)))
+}
+
+// Sign signs r by using key, and sets the address of key as r's signer.
+func (r *Receipt) Sign(key *ecdsa.PrivateKey) error {
+	r.Signer = strings.ToLower(crypto.PubkeyToAddress(key.PublicKey).Hex())
+	sig, err := crypto.Sign(r.digest(), key)
+	if err != nil {
+		return err
+	}
+	r.Signature = hexutil.Encode(sig)
+	return nil
+}
+
+// CheckSignature verifies offline that r has been signed by any of signerIDs.
+func (r Receipt) CheckSignature(signerIDs []string) error {
+	if r.Signature == "" {
+		return fmt.Errorf("receipt is not signed")
+	}
+	sig, err := hexutil.Decode(r.Signature)
+	if err != nil {
+		return fmt.Errorf("receipt signature is not valid: %s", err)
+	}
+	pub, err := crypto.SigToPub(r.digest(), sig)
+	if err != nil {
+		return fmt.Errorf("receipt signature is not valid: %s", err)
+	}
+	if strings.ToLower(crypto.PubkeyToAddress(*pub).Hex()) != r.Signer {
+		return fmt.Errorf("receipt signature is not valid: signer does not match")
+	}
+	for _, id := range signerIDs {
+		if strings.ToLower(common.HexToAddress(id).Hex()) == r.Signer {
+			return nil
+		}
+	}
+	return fmt.Errorf("receipt is signed by %s, which is not a trusted receipt signer", r.Signer)
+}
+
+// Check verifies offline that r is consistent and matches the given hash.
+// It does not verify r's signature (see CheckSignature).
+func (r Receipt) Check(hash string) error {
+	if r.Hash != hash {
+		return fmt.Errorf("receipt does not match: expected hash %s, got %s", r.Hash, hash)
+	}
+	if r.MetaHash != r.Verification.MetaHash() {
+		return fmt.Errorf("receipt is corrupted: metahash does not match its verification")
+	}
+	return nil
+}
+
+// CheckOnline verifies against the blockchain that r is still the most recent
+// notarization made by its owner.
+// Since the owner is taken from r itself, r's signature must be checked first (see CheckSignature).
+func (r Receipt) CheckOnline(ctx context.Context) (*BlockchainVerification, error) {
+	logger().WithFields(logrus.Fields{
+		"hash":     r.Hash,
+		"metahash": r.MetaHash,
+	}).Trace("CheckOnline")
+
+	l := ledger()
+	if contract := strings.ToLower(l.AssetsRelay().Hex()); contract != r.Contract {
+		return nil, fmt.Errorf("receipt contract %s does not match the current one (%s)", r.Contract, contract)
+	}
+
+	v, err := l.VerifyAgainstPublishers(ctx, r.Hash, []common.Address{r.Verification.Owner})
+	if err != nil {
+		return nil, err
+	}
+	if v.MetaHash() != r.MetaHash {
+		return v, fmt.Errorf("receipt for %s is outdated: a newer notarization has been found", r.Hash)
+	}
+	return v, nil
+}
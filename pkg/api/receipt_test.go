@@ -0,0 +1,112 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package api
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/vchain-us/vcn/pkg/meta"
+)
+
+func TestReceipt(t *testing.T) {
+	l := newMemLedger()
+	SetLedger(l)
+	defer SetLedger(nil)
+
+	alice := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	hash := "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+	_, err := NewReceipt(context.Background(), hash, &BlockchainVerification{Status: meta.StatusUnknown}, "")
+	assert.Error(t, err)
+
+	l.Sign(context.Background(), &bind.TransactOpts{From: alice}, hash, meta.StatusTrusted)
+	v, err := Verify(hash)
+	assert.NoError(t, err)
+
+	r, err := NewReceipt(context.Background(), hash, v, "0x01")
+	assert.NoError(t, err)
+	assert.Equal(t, v.MetaHash(), r.MetaHash)
+	assert.Equal(t, uint64(1), r.BlockNumber)
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := strings.ToLower(crypto.PubkeyToAddress(key.PublicKey).Hex())
+	assert.Error(t, r.CheckSignature([]string{signer}))
+	assert.NoError(t, r.Sign(key))
+	assert.Equal(t, signer, r.Signer)
+
+	tdir, err := ioutil.TempDir("", "vcn-receipt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tdir)
+	filename := filepath.Join(tdir, "receipt.json")
+
+	assert.NoError(t, r.WriteFile(filename))
+	loaded, err := LoadReceipt(filename)
+	assert.NoError(t, err)
+	assert.Equal(t, r.MetaHash, loaded.Verification.MetaHash())
+	assert.Equal(t, common.HexToHash("0x01").Hex(), loaded.TxHash)
+	assert.Equal(t, uint64(1), loaded.BlockNumber)
+
+	// offline
+	assert.NoError(t, loaded.Check(hash))
+	assert.Error(t, loaded.Check("not-matching"))
+
+	tampered := *loaded
+	tampered.Verification.Level = meta.LevelVchain
+	assert.Error(t, tampered.Check(hash))
+
+	// signature
+	assert.NoError(t, loaded.CheckSignature([]string{signer}))
+	assert.NoError(t, loaded.CheckSignature([]string{strings.ToUpper(signer[2:])}))
+	assert.Error(t, loaded.CheckSignature(nil))
+	assert.Error(t, loaded.CheckSignature([]string{alice.Hex()}))
+
+	// a forged receipt, consistent but not signed by the trusted signer
+	forged := *loaded
+	forged.Verification.Level = meta.LevelVchain
+	forged.MetaHash = forged.Verification.MetaHash()
+	assert.NoError(t, forged.Check(hash))
+	assert.Error(t, forged.CheckSignature([]string{signer}))
+
+	// a different block
+	moved := *loaded
+	moved.BlockNumber = 2
+	assert.Error(t, moved.CheckSignature([]string{signer}))
+
+	// re-signed by someone else
+	other, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, forged.Sign(other))
+	assert.Error(t, forged.CheckSignature([]string{signer}))
+
+	// online
+	ov, err := loaded.CheckOnline(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, ov.Trusted())
+
+	l.Sign(context.Background(), &bind.TransactOpts{From: alice}, hash, meta.StatusUntrusted)
+	ov, err = loaded.CheckOnline(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, meta.StatusUntrusted, ov.Status)
+}
@@ -0,0 +1,136 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package api
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RetryPolicy controls how idempotent platform and blockchain calls are retried on transient failures.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first one.
+	// A value lower than 2 disables retries.
+	MaxAttempts uint
+	// BaseDelay is the delay before the first retry, it doubles at each subsequent retry.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between two attempts.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy is the RetryPolicy used unless SetRetryPolicy is called.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    8 * time.Second,
+}
+
+var (
+	retryMu     sync.RWMutex
+	retryPolicy = DefaultRetryPolicy
+)
+
+// SetRetryPolicy sets the RetryPolicy for all subsequent calls.
+func SetRetryPolicy(p RetryPolicy) {
+	retryMu.Lock()
+	defer retryMu.Unlock()
+	retryPolicy = p
+}
+
+func getRetryPolicy() RetryPolicy {
+	retryMu.RLock()
+	defer retryMu.RUnlock()
+	return retryPolicy
+}
+
+// backoff returns the jittered delay to wait after the given (1-based) failed attempt,
+// that is a random value between half and the whole of the exponential delay.
+func (p RetryPolicy) backoff(attempt uint) time.Duration {
+	d := p.BaseDelay
+	for i := uint(1); i < attempt && d < p.MaxDelay; i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// retry calls fn until it returns again == false, the maximum number of attempts is reached,
+// or ctx is done. The last error returned by fn is returned, or ctx.Err() if ctx is done while waiting.
+// fn must only perform idempotent operations.
+func retry(ctx context.Context, op string, fn func() (again bool, err error)) error {
+	p := getRetryPolicy()
+	for attempt := uint(1); ; attempt++ {
+		again, err := fn()
+		if !again || attempt >= p.MaxAttempts || ctx.Err() != nil {
+			return err
+		}
+		delay := p.backoff(attempt)
+		logger().WithFields(logrus.Fields{
+			"op":          op,
+			"attempt":     attempt,
+			"maxAttempts": p.MaxAttempts,
+			"delay":       delay,
+			"error":       err,
+		}).Warn("Transient failure, retrying")
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+var httpServerErrorRegExp = regexp.MustCompile(`^5\d\d `)
+
+// isTransient returns true if err is likely due to a temporary network or server failure,
+// that is a timeout, a refused or reset connection, an unexpected EOF or a 5xx HTTP status.
+// Other failures (e.g. TLS errors or malformed URLs) are permanent.
+func isTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	if e, ok := err.(*url.Error); ok {
+		err = e.Err
+	}
+	switch err {
+	case context.Canceled, context.DeadlineExceeded:
+		return false
+	case io.EOF, io.ErrUnexpectedEOF:
+		return true
+	}
+	if e, ok := err.(net.Error); ok && (e.Timeout() || e.Temporary()) {
+		return true
+	}
+	if e, ok := err.(*net.OpError); ok {
+		cause := e.Err
+		if se, ok := cause.(*os.SyscallError); ok {
+			cause = se.Err
+		}
+		if cause == syscall.ECONNREFUSED || cause == syscall.ECONNRESET {
+			return true
+		}
+	}
+	// go-ethereum's RPC client reports HTTP failures by their status (eg. "502 Bad Gateway")
+	return httpServerErrorRegExp.MatchString(err.Error())
+}
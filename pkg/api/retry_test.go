@@ -0,0 +1,160 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package api
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/dghubble/sling"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoff(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 10, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	for attempt, max := range map[uint]time.Duration{
+		1: 100 * time.Millisecond,
+		2: 200 * time.Millisecond,
+		3: 400 * time.Millisecond,
+		5: time.Second,
+		9: time.Second,
+	} {
+		d := p.backoff(attempt)
+		assert.True(t, d >= max/2 && d <= max, "attempt %d: %s", attempt, d)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	SetRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})
+	defer SetRetryPolicy(DefaultRetryPolicy)
+
+	ctx := context.Background()
+
+	// transient
+	n := 0
+	err := retry(ctx, "test", func() (bool, error) {
+		n++
+		return true, fmt.Errorf("transient")
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 3, n)
+
+	// recovered
+	n = 0
+	err = retry(ctx, "test", func() (bool, error) {
+		n++
+		if n < 2 {
+			return true, fmt.Errorf("transient")
+		}
+		return false, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	// permanent
+	n = 0
+	err = retry(ctx, "test", func() (bool, error) {
+		n++
+		return false, fmt.Errorf("permanent")
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1, n)
+
+	// canceled
+	n = 0
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	retry(cctx, "test", func() (bool, error) {
+		n++
+		return true, fmt.Errorf("transient")
+	})
+	assert.Equal(t, 1, n)
+}
+
+func TestIsTransient(t *testing.T) {
+	assert.False(t, isTransient(nil))
+	assert.False(t, isTransient(fmt.Errorf("execution reverted")))
+	assert.True(t, isTransient(fmt.Errorf("502 Bad Gateway")))
+	assert.False(t, isTransient(fmt.Errorf("404 Not Found")))
+
+	// network failures
+	assert.True(t, isTransient(&url.Error{Op: "Get", URL: "http://localhost", Err: io.EOF}))
+	assert.True(t, isTransient(&url.Error{Op: "Get", URL: "http://localhost", Err: &net.OpError{
+		Op:  "dial",
+		Net: "tcp",
+		Err: os.NewSyscallError("connect", syscall.ECONNREFUSED),
+	}}))
+	assert.True(t, isTransient(&url.Error{Op: "Get", URL: "http://localhost", Err: &net.OpError{
+		Op:  "read",
+		Net: "tcp",
+		Err: os.NewSyscallError("read", syscall.ECONNRESET),
+	}}))
+	assert.True(t, isTransient(&net.DNSError{Err: "timeout", Name: "localhost", IsTimeout: true}))
+	assert.False(t, isTransient(&url.Error{Op: "Get", URL: "http://localhost", Err: context.DeadlineExceeded}))
+	assert.False(t, isTransient(&url.Error{Op: "Get", URL: "http://localhost", Err: context.Canceled}))
+	assert.False(t, isTransient(&url.Error{Op: "Get", URL: "https://localhost", Err: x509.UnknownAuthorityError{}}))
+	assert.False(t, isTransient(&url.Error{Op: "Get", URL: "ftp://localhost", Err: fmt.Errorf("unsupported protocol scheme \"ftp\"")}))
+
+	// against a real closed port
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+	_, err = http.Get("http://" + addr)
+	assert.True(t, isTransient(err), "%v", err)
+	_, err = http.Get("://" + addr)
+	assert.False(t, isTransient(err), "%v", err)
+}
+
+func TestReceiveRetry(t *testing.T) {
+	SetRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})
+	defer SetRetryPolicy(DefaultRetryPolicy)
+
+	calls := map[string]int{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls[r.Method]++
+		if r.Method == http.MethodGet && calls[r.Method] == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"count": 1}`)
+	}))
+	defer ts.Close()
+
+	response := struct {
+		Count uint64 `json:"count"`
+	}{}
+	r, err := receive(context.Background(), sling.New().Get(ts.URL), &response, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, r.StatusCode)
+	assert.Equal(t, uint64(1), response.Count)
+	assert.Equal(t, 2, calls[http.MethodGet])
+
+	// non-idempotent requests are never retried
+	r, _ = receive(context.Background(), sling.New().Post(ts.URL), nil, nil)
+	assert.Equal(t, http.StatusBadGateway, r.StatusCode)
+	assert.Equal(t, 1, calls[http.MethodPost])
+}
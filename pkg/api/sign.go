@@ -14,13 +14,10 @@ import (
 	"fmt"
 	"math/big"
 	"strings"
-	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/sirupsen/logrus"
-	"github.com/vchain-us/vcn/internal/blockchain"
 	"github.com/vchain-us/vcn/internal/errors"
 	"github.com/vchain-us/vcn/pkg/meta"
 )
@@ -33,99 +30,211 @@ var WrongPassphraseErr = goErr.New("incorrect notarization password")
 // By default, the artifact is notarized using status = meta.StatusTrusted, visibility meta.VisibilityPrivate.
 // At least the key (secret) must be provided using SignWithKey().
 func (u User) Sign(artifact Artifact, options ...SignOption) (*BlockchainVerification, error) {
+	return u.SignContext(context.Background(), artifact, options...)
+}
+
+// SignContext is like Sign, but all platform and blockchain calls are bound to ctx.
+// If ctx is done while waiting for the transaction, ctx.Err() is returned.
+func (u User) SignContext(ctx context.Context, artifact Artifact, options ...SignOption) (*BlockchainVerification, error) {
+	if err := checkArtifact(artifact); err != nil {
+		return nil, err
+	}
+
+	if err := u.checkCanSign(ctx, 1); err != nil {
+		return nil, err
+	}
+
+	return u.commitTransaction(
+		ctx,
+		artifact,
+		options...,
+	)
+}
+
+// SignBatch is like Sign, but notarizes all the given artifacts at once.
+// User's permissions are checked and the key is decrypted only once,
+// then all transactions are submitted using sequential nonces before waiting for any of them.
+//
+// The returned verifications and errs have the same length and order of artifacts:
+// for each artifact, either a BlockchainVerification or an error is returned.
+// A non-nil err is returned only if the whole batch failed before submitting any transaction.
+func (u User) SignBatch(artifacts []Artifact, options ...SignOption) (verifications []*BlockchainVerification, errs []error, err error) {
+	return u.SignBatchContext(context.Background(), artifacts, options...)
+}
+
+// SignBatchContext is like SignBatch, but all platform and blockchain calls are bound to ctx.
+func (u User) SignBatchContext(ctx context.Context, artifacts []Artifact, options ...SignOption) (verifications []*BlockchainVerification, errs []error, err error) {
+	if len(artifacts) == 0 {
+		return nil, nil, makeError("no artifacts to sign", nil)
+	}
+	for _, artifact := range artifacts {
+		if err = checkArtifact(artifact); err != nil {
+			return
+		}
+	}
+
+	if err = u.checkCanSign(ctx, uint64(len(artifacts))); err != nil {
+		return
+	}
+
+	o, err := makeSignOpts(u, options...)
+	if err != nil {
+		return
+	}
+
+	transactor, err := newTransactor(o)
+	if err != nil {
+		return
+	}
+
+	l := o.ledger
+	if l == nil {
+		l = ledger()
+	}
+
+	nonce, err := l.PendingNonce(ctx, transactor.From)
+	if err != nil {
+		return
+	}
+
+	verifications = make([]*BlockchainVerification, len(artifacts))
+	errs = make([]error, len(artifacts))
+	txs := make([]common.Hash, len(artifacts))
+
+	// submit all transactions
+	var abort error
+	for i, artifact := range artifacts {
+		if abort != nil {
+			errs[i] = abort
+			continue
+		}
+		t := *transactor
+		t.Nonce = new(big.Int).SetUint64(nonce)
+		txs[i], errs[i] = l.Sign(ctx, &t, artifact.Hash, o.status)
+		if errs[i] == nil {
+			nonce++
+			continue
+		}
+		// the failed transaction may have been broadcast (or even mined) anyway,
+		// so the next nonce must be fetched again
+		var nerr error
+		if nonce, nerr = l.PendingNonce(ctx, transactor.From); nerr != nil {
+			abort = fmt.Errorf("not submitted, since the nonce could not be retrieved: %s", nerr)
+		}
+	}
+
+	// then wait for them
+	for i, artifact := range artifacts {
+		if errs[i] != nil {
+			continue
+		}
+		verifications[i], errs[i] = u.confirmTransaction(ctx, l, o, transactor, artifact, txs[i])
+	}
+
+	return
+}
+
+func checkArtifact(artifact Artifact) error {
 	if artifact.Hash == "" {
-		return nil, makeError("hash is missing", nil)
+		return makeError("hash is missing", nil)
 	}
 	if artifact.Size < 0 {
-		return nil, makeError("invalid size", nil)
+		return makeError("invalid size", nil)
 	}
+	return nil
+}
 
-	hasAuth, err := u.IsAuthenticated()
+// checkCanSign checks that u is allowed to make n notarizations.
+func (u User) checkCanSign(ctx context.Context, n uint64) error {
+	hasAuth, err := u.isAuthenticated(ctx)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	if !hasAuth {
-		return nil, makeAuthRequiredError()
+		return makeAuthRequiredError()
 	}
 
-	trialExpired, err := u.trialExpired()
+	trialExpired, err := u.trialExpired(ctx)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	if trialExpired {
-		return nil, fmt.Errorf(errors.TrialExpired)
+		return fmt.Errorf(errors.TrialExpired)
 	}
 
-	opsLeft, err := u.RemainingSignOps()
+	opsLeft, err := u.remainingSignOps(ctx)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	if opsLeft < 1 {
-		return nil, fmt.Errorf(errors.NoRemainingSignOps)
+		return fmt.Errorf(errors.NoRemainingSignOps)
+	}
+	if opsLeft < n {
+		return fmt.Errorf(errors.NotEnoughSignOps, n, opsLeft)
 	}
 
-	return u.commitTransaction(
-		artifact,
-		options...,
-	)
+	return nil
 }
 
-func (u User) commitTransaction(
-	artifact Artifact,
-	opts ...SignOption,
-) (verification *BlockchainVerification, err error) {
-
-	o, err := makeSignOpts(u, opts...)
-	if err != nil {
-		return
-	}
-
+func newTransactor(o *signOpts) (*bind.TransactOpts, error) {
 	transactor, err := bind.NewTransactor(o.keyin, o.passphrase)
 	if err != nil {
 		if err.Error() == "could not decrypt key with given passphrase" {
 			err = WrongPassphraseErr
 		}
-		return
+		return nil, err
 	}
 
 	transactor.GasLimit = meta.GasLimit()
 	transactor.GasPrice = meta.GasPrice()
-	client, err := ethclient.Dial(meta.MainNet())
+	return transactor, nil
+}
+
+func (u User) commitTransaction(
+	ctx context.Context,
+	artifact Artifact,
+	opts ...SignOption,
+) (verification *BlockchainVerification, err error) {
+
+	o, err := makeSignOpts(u, opts...)
 	if err != nil {
-		err = makeError(
-			errors.BlockchainCannotConnect,
-			logrus.Fields{
-				"error":   err,
-				"network": meta.MainNet(),
-			})
 		return
 	}
-	address := common.HexToAddress(meta.AssetsRelayContractAddress())
-	instance, err := blockchain.NewAssetsRelay(address, client)
+
+	transactor, err := newTransactor(o)
 	if err != nil {
-		err = makeFatal(
-			errors.BlockchainContractErr,
-			logrus.Fields{
-				"error":    err,
-				"contract": meta.AssetsRelayContractAddress(),
-			},
-		)
 		return
 	}
-	tx, err := instance.Sign(transactor, artifact.Hash, big.NewInt(int64(o.status)))
+
+	l := o.ledger
+	if l == nil {
+		l = ledger()
+	}
+
+	tx, err := l.Sign(ctx, transactor, artifact.Hash, o.status)
 	if err != nil {
-		err = makeFatal(
-			errors.SignFailed,
-			logrus.Fields{
-				"error": err,
-				"hash":  artifact.Hash,
-			},
-		)
 		return
 	}
-	timeout, err := waitForTx(tx.Hash(), meta.TxVerificationRounds(), meta.PollInterval())
+
+	return u.confirmTransaction(ctx, l, o, transactor, artifact, tx)
+}
+
+// confirmTransaction waits for tx to be mined, then stores the artifact onto the platform.
+func (u User) confirmTransaction(
+	ctx context.Context,
+	l Ledger,
+	o *signOpts,
+	transactor *bind.TransactOpts,
+	artifact Artifact,
+	tx common.Hash,
+) (verification *BlockchainVerification, err error) {
+
+	timeout, err := l.WaitForTx(ctx, tx)
 	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 		err = makeFatal(
 			errors.BlockchainPermission,
 			logrus.Fields{
@@ -145,29 +254,13 @@ func (u User) commitTransaction(
 	}
 
 	signerID := transactor.From.Hex()
-	verification, err = VerifyMatchingSignerID(artifact.Hash, signerID)
+	verification, err = l.VerifyAgainstPublishers(ctx, artifact.Hash, []common.Address{transactor.From})
 	if err != nil {
 		return
 	}
 
-	err = u.createArtifact(verification, strings.ToLower(signerID), artifact, o.visibility, o.status, tx.Hash())
-	return
-}
+	invalidateCache(artifact.Hash)
 
-func waitForTx(tx common.Hash, maxRounds uint64, pollInterval time.Duration) (timeout bool, err error) {
-	client, err := ethclient.Dial(meta.MainNet())
-	if err != nil {
-		return false, err
-	}
-	for i := uint64(0); i < maxRounds; i++ {
-		_, pending, err := client.TransactionByHash(context.Background(), tx)
-		if err != nil {
-			return false, err
-		}
-		if !pending {
-			return false, nil
-		}
-		time.Sleep(pollInterval)
-	}
-	return true, nil
+	err = u.createArtifact(ctx, verification, strings.ToLower(signerID), artifact, o.visibility, o.status, tx)
+	return
 }
@@ -22,6 +22,7 @@ type signOpts struct {
 	visibility meta.Visibility
 	keyin      io.Reader
 	passphrase string
+	ledger     Ledger
 }
 
 func makeSignOpts(u User, opts ...SignOption) (o *signOpts, err error) {
@@ -66,3 +67,12 @@ func SignWithKey(keyin io.Reader, passphrase string) SignOption {
 		return nil
 	}
 }
+
+// SignWithLedger returns the functional option for the given ledger.
+// If not provided, the Ledger set by SetLedger() is used.
+func SignWithLedger(l Ledger) SignOption {
+	return func(o *signOpts) error {
+		o.ledger = l
+		return nil
+	}
+}
@@ -63,3 +63,12 @@ func TestSignWithKey(t *testing.T) {
 	assert.Equal(t, reader, o.keyin)
 	assert.Equal(t, pass, o.passphrase)
 }
+
+func TestSignWithLedger(t *testing.T) {
+	l := newMemLedger()
+
+	o := &signOpts{}
+	SignWithLedger(l)(o)
+
+	assert.Equal(t, l, o.ledger)
+}
@@ -10,6 +10,7 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -85,11 +86,20 @@ func (u *User) token() string {
 
 // IsAuthenticated returns true if the stored auth token is still valid.
 func (u User) IsAuthenticated() (bool, error) {
+	return u.isAuthenticated(context.Background())
+}
+
+// IsAuthenticatedContext is like IsAuthenticated, but the request is bound to ctx.
+func (u User) IsAuthenticatedContext(ctx context.Context) (bool, error) {
+	return u.isAuthenticated(ctx)
+}
+
+func (u User) isAuthenticated(ctx context.Context) (bool, error) {
 	if u.cfg == nil || u.cfg.Token == "" {
 		return false, nil
 	}
 
-	return checkToken(u.cfg.Token)
+	return checkToken(ctx, u.cfg.Token)
 }
 
 // IsExist returns true if the User's was registered on the CodeNotary platform.
@@ -122,9 +132,9 @@ func (u User) getWallet() (address, keystore string, offline bool, err error) {
 			LevelSyncState      string `json:"levelSyncState"`
 		} `json:"content"`
 	})
-	r, err := newSling(u.token()).
-		Get(meta.APIEndpoint("wallet")).
-		Receive(pagedWalletResponse, authError)
+	r, err := receive(context.Background(), newSling(u.token()).
+		Get(meta.APIEndpoint("wallet")),
+		pagedWalletResponse, authError)
 	logger().WithFields(logrus.Fields{
 		"response":  "HIDDEN",
 		"err":       err,
@@ -133,6 +143,10 @@ func (u User) getWallet() (address, keystore string, offline bool, err error) {
 	if err != nil {
 		return
 	}
+	if r.StatusCode == 401 {
+		err = makeAuthRequiredError()
+		return
+	}
 	if r.StatusCode != 200 {
 		err = fmt.Errorf("request failed: %s (%d)", authError.Message, authError.Status)
 		return
@@ -232,13 +246,17 @@ func (u User) UploadSecret(secret io.Reader, passphrase string) (err error) {
 
 // RemainingSignOps returns the number of remaining notarizations in the User's account subscription.
 func (u User) RemainingSignOps() (uint64, error) {
+	return u.remainingSignOps(context.Background())
+}
+
+func (u User) remainingSignOps(ctx context.Context) (uint64, error) {
 	response := new(struct {
 		Count uint64 `json:"count"`
 	})
 	restError := new(Error)
-	r, err := newSling(u.token()).
-		Get(meta.APIEndpoint("artifact/remaining-sign-operations")).
-		Receive(&response, restError)
+	r, err := receive(ctx, newSling(u.token()).
+		Get(meta.APIEndpoint("artifact/remaining-sign-operations")),
+		&response, restError)
 	logger().WithFields(logrus.Fields{
 		"response":  response,
 		"err":       err,
@@ -254,14 +272,14 @@ func (u User) RemainingSignOps() (uint64, error) {
 	return 0, fmt.Errorf("count remaining sign operations failed: %+v", restError)
 }
 
-func (u User) trialExpired() (bool, error) {
+func (u User) trialExpired(ctx context.Context) (bool, error) {
 	response := new(struct {
 		TrialExpired bool `json:"trialExpired"`
 	})
 	restError := new(Error)
-	r, err := newSling(u.token()).
-		Get(publisherEndpoint()).
-		Receive(&response, restError)
+	r, err := receive(ctx, newSling(u.token()).
+		Get(publisherEndpoint()),
+		&response, restError)
 	logger().WithFields(logrus.Fields{
 		"response":  response,
 		"err":       err,
@@ -9,7 +9,10 @@
 package api
 
 import (
+	"context"
+	goErr "errors"
 	"fmt"
+	"net/http"
 
 	"github.com/dghubble/sling"
 	"github.com/sirupsen/logrus"
@@ -34,8 +37,13 @@ func makeFatal(msg string, fields logrus.Fields) error {
 	return err
 }
 
+// AuthRequiredErr is returned when the user is not logged in, or the platform rejects
+// the user's token (e.g. because it has expired).
+var AuthRequiredErr = goErr.New(errors.AuthRequired)
+
 func makeAuthRequiredError() error {
-	return makeError(errors.AuthRequired, nil)
+	logger().Error(AuthRequiredErr)
+	return AuthRequiredErr
 }
 
 func contains(xs []string, x string) bool {
@@ -47,6 +55,27 @@ func contains(xs []string, x string) bool {
 	return false
 }
 
+// receive is like (*sling.Sling).Receive, but the request is bound to ctx.
+// GET requests are retried, according to the current RetryPolicy, on network failures and 5xx responses.
+func receive(ctx context.Context, s *sling.Sling, successV, failureV interface{}) (res *http.Response, err error) {
+	req, err := s.Request()
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if req.Method != http.MethodGet {
+		return s.Do(req, successV, failureV)
+	}
+	err = retry(ctx, req.Method+" "+req.URL.Path, func() (bool, error) {
+		res, err = s.Do(req, successV, failureV)
+		if res != nil && res.StatusCode >= 500 {
+			return true, err
+		}
+		return isTransient(err), err
+	})
+	return
+}
+
 func newSling(token string) (s *sling.Sling) {
 	s = sling.New()
 	s.Add("User-Agent", meta.UserAgent())
@@ -9,6 +9,7 @@
 package api
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
@@ -17,9 +18,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/sirupsen/logrus"
-	"github.com/vchain-us/vcn/internal/blockchain"
 	"github.com/vchain-us/vcn/pkg/meta"
 )
 
@@ -140,62 +139,38 @@ func (v *BlockchainVerification) Date() string {
 	return ""
 }
 
-func callVerifyFunc(f func(*blockchain.AssetsRelay) (common.Address, *big.Int, *big.Int, *big.Int, error)) (*BlockchainVerification, error) {
-	client, err := ethclient.Dial(meta.MainNet())
-	if err != nil {
-		return nil, err
-	}
-	contractAddress := common.HexToAddress(meta.AssetsRelayContractAddress())
-	instance, err := blockchain.NewAssetsRelay(contractAddress, client)
-	if err != nil {
-		return nil, err
-	}
-	address, level, status, timestamp, err := f(instance)
-	if err != nil {
-		return nil, err
-	}
-	if meta.Status(status.Int64()) != meta.StatusUnknown && address != common.BigToAddress(big.NewInt(0)) {
-		verification := &BlockchainVerification{
-			Owner:     address,
-			Level:     meta.Level(level.Int64()),
-			Status:    meta.Status(status.Int64()),
-			Timestamp: time.Unix(timestamp.Int64(), 0),
-		}
-		logger().
-			WithField("verification", verification).
-			Trace("Blockchain verification found")
-		return verification, nil
-	}
-
-	logger().Trace("No blockchain verification found")
-	return &BlockchainVerification{
-		Status: meta.StatusUnknown,
-	}, nil
-}
-
 // Verify returns the most recent *BlockchainVerification with highest level available for the given hash.
 func Verify(hash string) (*BlockchainVerification, error) {
+	return VerifyContext(context.Background(), hash)
+}
+
+// VerifyContext is like Verify, but the blockchain call is bound to ctx.
+func VerifyContext(ctx context.Context, hash string) (*BlockchainVerification, error) {
 	logger().WithFields(logrus.Fields{
 		"hash": hash,
 	}).Trace("Verify")
 
-	return callVerifyFunc(func(instance *blockchain.AssetsRelay) (common.Address, *big.Int, *big.Int, *big.Int, error) {
-		return instance.Verify(nil, hash)
+	return cached(cacheScopeAny, hash, nil, func() (*BlockchainVerification, error) {
+		return ledger().Verify(ctx, hash)
 	})
 }
 
 // VerifyMatchingSignerIDWithFallback returns *BlockchainVerification for the hash matching a given SignerID,
 // if any, otherwise it returns the same result of Verify().
 func VerifyMatchingSignerIDWithFallback(hash string, signerID string) (*BlockchainVerification, error) {
+	return VerifyMatchingSignerIDWithFallbackContext(context.Background(), hash, signerID)
+}
+
+// VerifyMatchingSignerIDWithFallbackContext is like VerifyMatchingSignerIDWithFallback,
+// but the blockchain call is bound to ctx.
+func VerifyMatchingSignerIDWithFallbackContext(ctx context.Context, hash string, signerID string) (*BlockchainVerification, error) {
 	logger().WithFields(logrus.Fields{
 		"hash":     hash,
 		"signerID": signerID,
 	}).Trace("VerifyMatchingSignerIDWithFallback")
 
-	address := common.HexToAddress(signerID)
-
-	return callVerifyFunc(func(instance *blockchain.AssetsRelay) (common.Address, *big.Int, *big.Int, *big.Int, error) {
-		return instance.VerifyAgainstPublisherWithFallback(nil, hash, address)
+	return cached(cacheScopeFallback, hash, []string{signerID}, func() (*BlockchainVerification, error) {
+		return ledger().VerifyAgainstPublisherWithFallback(ctx, hash, common.HexToAddress(signerID))
 	})
 }
 
@@ -207,6 +182,11 @@ func VerifyMatchingSignerID(hash string, signerID string) (*BlockchainVerificati
 // VerifyMatchingSignerIDs returns *BlockchainVerification for hash
 // matching at least one of signerIDs.
 func VerifyMatchingSignerIDs(hash string, signerIDs []string) (*BlockchainVerification, error) {
+	return VerifyMatchingSignerIDsContext(context.Background(), hash, signerIDs)
+}
+
+// VerifyMatchingSignerIDsContext is like VerifyMatchingSignerIDs, but the blockchain call is bound to ctx.
+func VerifyMatchingSignerIDsContext(ctx context.Context, hash string, signerIDs []string) (*BlockchainVerification, error) {
 	logger().WithFields(logrus.Fields{
 		"hash":      hash,
 		"signerIDs": signerIDs,
@@ -217,7 +197,7 @@ func VerifyMatchingSignerIDs(hash string, signerIDs []string) (*BlockchainVerifi
 		addresses[i] = common.HexToAddress(s)
 	}
 
-	return callVerifyFunc(func(instance *blockchain.AssetsRelay) (common.Address, *big.Int, *big.Int, *big.Int, error) {
-		return instance.VerifyAgainstPublishers(nil, hash, addresses)
+	return cached(cacheScopeSigners, hash, signerIDs, func() (*BlockchainVerification, error) {
+		return ledger().VerifyAgainstPublishers(ctx, hash, addresses)
 	})
 }
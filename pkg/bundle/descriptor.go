@@ -10,6 +10,7 @@ package bundle
 
 import (
 	"io"
+	"os"
 	"sort"
 
 	// See https://github.com/opencontainers/go-digest#usage
@@ -29,6 +30,49 @@ type Descriptor struct {
 
 	// Paths specifies the relative locations of the targeted content.
 	Paths []string `json:"paths"`
+
+	// Mode specifies the kind and the permissions of the targeted content (since schema version 2),
+	// as one of ModeFile, ModeExecutable, ModeSymlink or ModeDir.
+	Mode string `json:"mode,omitempty"`
+
+	// Target specifies the destination of a symbolic link (since schema version 2).
+	Target string `json:"target,omitempty"`
+}
+
+// Descriptor's modes, values are the same used by git.
+const (
+	// ModeFile is the mode of regular files.
+	ModeFile = "100644"
+
+	// ModeExecutable is the mode of regular files having at least one executable bit set.
+	ModeExecutable = "100755"
+
+	// ModeSymlink is the mode of symbolic links, their content is the link's target.
+	ModeSymlink = "120000"
+
+	// ModeDir is the mode of empty directories, their content is always empty.
+	ModeDir = "040000"
+)
+
+var modes = map[string]bool{
+	ModeFile:       true,
+	ModeExecutable: true,
+	ModeSymlink:    true,
+	ModeDir:        true,
+}
+
+// FileMode returns the descriptor's mode corresponding to fm.
+func FileMode(fm os.FileMode) string {
+	switch true {
+	case fm&os.ModeSymlink != 0:
+		return ModeSymlink
+	case fm.IsDir():
+		return ModeDir
+	case fm&0111 != 0:
+		return ModeExecutable
+	default:
+		return ModeFile
+	}
 }
 
 func (d *Descriptor) sortUnique() {
@@ -59,3 +103,24 @@ func NewDescriptor(path string, src io.Reader) (*Descriptor, error) {
 		Size:   uint64(size),
 	}, nil
 }
+
+// NewSymlinkDescriptor returns a new *Descriptor for the symbolic link at path pointing to target.
+func NewSymlinkDescriptor(path string, target string) *Descriptor {
+	return &Descriptor{
+		Paths:  []string{path},
+		Digest: digest.SHA256.FromString(target),
+		Size:   uint64(len(target)),
+		Mode:   ModeSymlink,
+		Target: target,
+	}
+}
+
+// NewDirDescriptor returns a new *Descriptor for the empty directory at path.
+func NewDirDescriptor(path string) *Descriptor {
+	return &Descriptor{
+		Paths:  []string{path},
+		Digest: digest.SHA256.FromBytes(nil),
+		Size:   0,
+		Mode:   ModeDir,
+	}
+}
@@ -10,6 +10,7 @@ package bundle
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/dustin/go-humanize"
@@ -60,37 +61,152 @@ func (r *diffReporter) String() string {
 	return strings.Join(r.lines, "\n")
 }
 
-// DiffByPath returns a human-readable report as string containing
-// additions, modifications, renamings, deletions of x.Items relative to m.Items
-// listed by path.
+// DiffItem describes an item, by a single path, within a PathDiff.
+type DiffItem struct {
+	Path   string        `json:"path" yaml:"path"`
+	Digest digest.Digest `json:"digest" yaml:"digest"`
+	Size   uint64        `json:"size" yaml:"size"`
+	Mode   string        `json:"mode,omitempty" yaml:"mode,omitempty"`
+	Target string        `json:"target,omitempty" yaml:"target,omitempty"`
+}
+
+func newDiffItem(path string, d Descriptor) *DiffItem {
+	return &DiffItem{
+		Path:   path,
+		Digest: d.Digest,
+		Size:   d.Size,
+		Mode:   d.Mode,
+		Target: d.Target,
+	}
+}
+
+// DiffChange is a change of an item within a PathDiff.
+// From is nil for added items, To is nil for deleted items.
+type DiffChange struct {
+	From *DiffItem `json:"from,omitempty" yaml:"from,omitempty"`
+	To   *DiffItem `json:"to,omitempty" yaml:"to,omitempty"`
+}
+
+// PathDiff is a structured report of the differences by path between two manifests.
+// Each list is sorted by path.
+type PathDiff struct {
+	Added       []DiffChange `json:"added,omitempty" yaml:"added,omitempty"`
+	Modified    []DiffChange `json:"modified,omitempty" yaml:"modified,omitempty"`
+	ModeChanged []DiffChange `json:"modeChanged,omitempty" yaml:"modeChanged,omitempty"`
+	Retargeted  []DiffChange `json:"retargeted,omitempty" yaml:"retargeted,omitempty"`
+	Renamed     []DiffChange `json:"renamed,omitempty" yaml:"renamed,omitempty"`
+	Deleted     []DiffChange `json:"deleted,omitempty" yaml:"deleted,omitempty"`
+}
+
+// Equal returns true if d contains no differences.
+func (d *PathDiff) Equal() bool {
+	return d == nil || len(d.Added) == 0 && len(d.Modified) == 0 && len(d.ModeChanged) == 0 &&
+		len(d.Retargeted) == 0 && len(d.Renamed) == 0 && len(d.Deleted) == 0
+}
+
+// String returns a human-readable report of d, or an empty string if d is equal.
 //
 // Do not depend on this output being stable.
-func (m Manifest) DiffByPath(x Manifest) (report string, equal bool, err error) {
-	type modDiff struct {
-		path string
-		from Descriptor
-		to   Descriptor
+func (d *PathDiff) String() string {
+	if d.Equal() {
+		return ""
 	}
 
-	type pathDiff struct {
-		desc Descriptor
-		from string
-		to   string
+	lines := []string{}
+
+	sprintf := func(format string, a ...interface{}) {
+		lines = append(lines, fmt.Sprintf(format, a...))
 	}
 
-	type itemDiff struct {
-		path string
-		desc Descriptor
+	for _, c := range d.Added {
+		sprintf(
+			"\tnew item:   %s (%s)\n\t            + %s",
+			c.To.Path,
+			humanize.Bytes(c.To.Size),
+			c.To.Digest.String(),
+		)
+	}
+	for _, c := range d.Modified {
+		sprintf(
+			"\tmodified:   %s (%s -> %s)\n\t            - %s\n\t            + %s",
+			c.To.Path,
+			humanize.Bytes(c.From.Size),
+			humanize.Bytes(c.To.Size),
+			c.From.Digest.String(),
+			c.To.Digest.String(),
+		)
+	}
+	for _, c := range d.ModeChanged {
+		sprintf(
+			"\tmode changed: %s (%s -> %s)",
+			c.To.Path,
+			c.From.Mode,
+			c.To.Mode,
+		)
+	}
+	for _, c := range d.Retargeted {
+		sprintf(
+			"\tlink retargeted: %s\n\t            - %s\n\t            + %s",
+			c.To.Path,
+			c.From.Target,
+			c.To.Target,
+		)
+	}
+	for _, c := range d.Renamed {
+		sprintf(
+			"\trenamed:    %s -> %s (%s)\n\t            = %s",
+			c.From.Path,
+			c.To.Path,
+			humanize.Bytes(c.To.Size),
+			c.To.Digest.String(),
+		)
+	}
+	for _, c := range d.Deleted {
+		sprintf(
+			"\tdeleted:    %s (%s)\n\t            - %s",
+			c.From.Path,
+			humanize.Bytes(c.From.Size),
+			c.From.Digest.String(),
+		)
 	}
 
-	adds := make([]itemDiff, 0)
-	mods := make([]modDiff, 0)
-	rens := make([]pathDiff, 0)
-	dels := make([]itemDiff, 0)
+	return strings.Join(lines, "\n\n") + "\n"
+}
+
+// DiffByPath returns a structured report containing additions, modifications, mode changes,
+// symbolic link retargetings, renamings and deletions of x.Items relative to m.Items listed by path.
+//
+// When m and x follow distinct schema versions, both are compared as per the legacy one
+// (i.e. regular files only).
+func (m Manifest) DiffByPath(x Manifest) (*PathDiff, error) {
+	if m.SchemaVersion != x.SchemaVersion {
+		mm, err := m.V1()
+		if err != nil {
+			return nil, err
+		}
+		xx, err := x.V1()
+		if err != nil {
+			return nil, err
+		}
+		return mm.DiffByPath(*xx)
+	}
+	if err := m.Normalize(); err != nil {
+		return nil, err
+	}
+	if err := x.Normalize(); err != nil {
+		return nil, err
+	}
+
+	diff := &PathDiff{}
 
 	mByPath := make(map[string]Descriptor)
 	xByPath := make(map[string]Descriptor)
-	newPaths := make(map[digest.Digest][]string)
+	newPaths := make(map[string][]string)
+
+	// items are matched by content and mode, so that a renamed item keeps both
+	key := func(d Descriptor) string {
+		return d.Digest.String() + " " + d.Mode
+	}
 
 	for _, d := range x.Items {
 		for _, path := range d.Paths {
@@ -101,43 +217,58 @@ func (m Manifest) DiffByPath(x Manifest) (report string, equal bool, err error)
 		for _, path := range d.Paths {
 			mByPath[path] = d
 			if _, ok := xByPath[path]; !ok {
-				newPaths[d.Digest] = append(newPaths[d.Digest], path)
+				newPaths[key(d)] = append(newPaths[key(d)], path)
 			}
 		}
 	}
+	// renamings are detected in path order, so that results are deterministic
+	for k := range newPaths {
+		sort.Strings(newPaths[k])
+	}
+	xPaths := make([]string, 0, len(xByPath))
+	for path := range xByPath {
+		xPaths = append(xPaths, path)
+	}
+	sort.Strings(xPaths)
 
-	for path, xd := range xByPath {
+	for _, path := range xPaths {
+		xd := xByPath[path]
 
 		// try by path
 		if md, ok := mByPath[path]; ok {
-			if md.Digest != xd.Digest {
+			c := DiffChange{
+				From: newDiffItem(path, xd),
+				To:   newDiffItem(path, md),
+			}
+			switch true {
+			case md.Mode == ModeSymlink && xd.Mode == ModeSymlink && md.Target != xd.Target:
+				// link retargeted
+				diff.Retargeted = append(diff.Retargeted, c)
+			case md.Digest != xd.Digest:
 				// modified
-				mods = append(mods, modDiff{
-					path: path,
-					from: xd,
-					to:   md,
-				})
+				diff.Modified = append(diff.Modified, c)
+			case md.Mode != xd.Mode:
+				// mode changed
+				diff.ModeChanged = append(diff.ModeChanged, c)
 			}
 			// else:
-			// same content, so no diff
+			// same content and mode, so no diff
 
-		} else { // try by digest
-			byDig := xd.Digest
-			if mPaths, ok := newPaths[byDig]; ok && len(mPaths) > 0 {
+		} else { // try by content
+			byKey := key(xd)
+			if mPaths, ok := newPaths[byKey]; ok && len(mPaths) > 0 {
 				// renamed
 				newPath := mPaths[0]
-				newPaths[byDig] = mPaths[1:]
-				rens = append(rens, pathDiff{
-					desc: xd,
-					from: path,
-					to:   newPath,
+				newPaths[byKey] = mPaths[1:]
+				diff.Renamed = append(diff.Renamed, DiffChange{
+					From: newDiffItem(path, xd),
+					To:   newDiffItem(newPath, mByPath[newPath]),
 				})
 				delete(mByPath, newPath)
 			} else {
 				// deleted
-				dels = append(dels, itemDiff{
-					path: path,
-					desc: xd,
+				diff.Deleted = append(diff.Deleted, DiffChange{
+					From: newDiffItem(path, xd),
 				})
 			}
 
@@ -148,59 +279,16 @@ func (m Manifest) DiffByPath(x Manifest) (report string, equal bool, err error)
 
 	// finally, arrange new items
 	for path, d := range mByPath {
-		adds = append(adds, itemDiff{
-			path: path,
-			desc: d,
+		diff.Added = append(diff.Added, DiffChange{
+			To: newDiffItem(path, d),
 		})
 	}
+	sort.Slice(diff.Added, func(i, j int) bool {
+		return diff.Added[i].To.Path < diff.Added[j].To.Path
+	})
+	sort.Slice(diff.Renamed, func(i, j int) bool {
+		return diff.Renamed[i].To.Path < diff.Renamed[j].To.Path
+	})
 
-	equal = len(adds) == 0 && len(mods) == 0 && len(rens) == 0 && len(dels) == 0
-	if equal {
-		return // empty diff, no need to format lines
-	}
-
-	lines := []string{}
-
-	sprintf := func(format string, a ...interface{}) {
-		lines = append(lines, fmt.Sprintf(format, a...))
-	}
-
-	for _, d := range adds {
-		sprintf(
-			"\tnew item:   %s (%s)\n\t            + %s",
-			d.path,
-			humanize.Bytes(d.desc.Size),
-			d.desc.Digest.String(),
-		)
-	}
-	for _, d := range mods {
-		sprintf(
-			"\tmodified:   %s (%s -> %s)\n\t            - %s\n\t            + %s",
-			d.path,
-			humanize.Bytes(d.from.Size),
-			humanize.Bytes(d.to.Size),
-			d.from.Digest.String(),
-			d.to.Digest.String(),
-		)
-	}
-	for _, d := range rens {
-		sprintf(
-			"\trenamed:    %s -> %s (%s)\n\t            = %s",
-			d.from,
-			d.to,
-			humanize.Bytes(d.desc.Size),
-			d.desc.Digest.String(),
-		)
-	}
-	for _, d := range dels {
-		sprintf(
-			"\tdeleted:    %s (%s)\n\t            - %s",
-			d.path,
-			humanize.Bytes(d.desc.Size),
-			d.desc.Digest.String(),
-		)
-	}
-
-	report = strings.Join(lines, "\n\n") + "\n"
-	return
+	return diff, nil
 }
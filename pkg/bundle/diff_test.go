@@ -0,0 +1,109 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package bundle
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffByPath(t *testing.T) {
+	file := func(path, content, mode string) Descriptor {
+		d, err := NewDescriptor(path, strings.NewReader(content))
+		assert.NoError(t, err)
+		d.Mode = mode
+		return *d
+	}
+
+	old := NewManifest(
+		file("a.txt", "a", ModeFile),
+		file("b.sh", "b", ModeExecutable),
+		file("c.txt", "c", ModeFile),
+		*NewSymlinkDescriptor("link", "a.txt"),
+		*NewDirDescriptor("empty"),
+	)
+
+	// same
+	diff, err := old.DiffByPath(*old)
+	assert.NoError(t, err)
+	assert.True(t, diff.Equal())
+	assert.Empty(t, diff.String())
+
+	cur := NewManifest(
+		file("a.txt", "a", ModeExecutable),
+		file("b.sh", "b", ModeExecutable),
+		file("c.txt", "changed", ModeFile),
+		*NewSymlinkDescriptor("link", "b.sh"),
+	)
+	diff, err = cur.DiffByPath(*old)
+	assert.NoError(t, err)
+	assert.False(t, diff.Equal())
+	report := diff.String()
+	assert.Contains(t, report, "mode changed: a.txt (100644 -> 100755)")
+	assert.Contains(t, report, "link retargeted: link\n\t            - a.txt\n\t            + b.sh")
+	assert.Contains(t, report, "modified:   c.txt")
+	assert.Contains(t, report, "deleted:    empty")
+	assert.NotContains(t, report, "b.sh (")
+
+	// structured
+	assert.Empty(t, diff.Added)
+	assert.Empty(t, diff.Renamed)
+	if assert.Len(t, diff.ModeChanged, 1) {
+		assert.Equal(t, "a.txt", diff.ModeChanged[0].To.Path)
+		assert.Equal(t, ModeFile, diff.ModeChanged[0].From.Mode)
+		assert.Equal(t, ModeExecutable, diff.ModeChanged[0].To.Mode)
+	}
+	if assert.Len(t, diff.Retargeted, 1) {
+		assert.Equal(t, "a.txt", diff.Retargeted[0].From.Target)
+		assert.Equal(t, "b.sh", diff.Retargeted[0].To.Target)
+	}
+	if assert.Len(t, diff.Modified, 1) {
+		assert.Equal(t, uint64(1), diff.Modified[0].From.Size)
+		assert.Equal(t, uint64(7), diff.Modified[0].To.Size)
+		assert.NotEqual(t, diff.Modified[0].From.Digest, diff.Modified[0].To.Digest)
+	}
+	if assert.Len(t, diff.Deleted, 1) {
+		assert.Equal(t, "empty", diff.Deleted[0].From.Path)
+		assert.Nil(t, diff.Deleted[0].To)
+	}
+
+	// added and renamed
+	renamed := NewManifest(
+		file("a.txt", "a", ModeFile),
+		file("bin/b.sh", "b", ModeExecutable),
+		file("c.txt", "c", ModeFile),
+		file("d.txt", "d", ModeFile),
+		*NewSymlinkDescriptor("link", "a.txt"),
+		*NewDirDescriptor("empty"),
+	)
+	diff, err = renamed.DiffByPath(*old)
+	assert.NoError(t, err)
+	if assert.Len(t, diff.Renamed, 1) {
+		assert.Equal(t, "b.sh", diff.Renamed[0].From.Path)
+		assert.Equal(t, "bin/b.sh", diff.Renamed[0].To.Path)
+	}
+	if assert.Len(t, diff.Added, 1) {
+		assert.Equal(t, "d.txt", diff.Added[0].To.Path)
+		assert.Nil(t, diff.Added[0].From)
+	}
+
+	// distinct versions are compared as per the legacy one
+	legacy, err := old.V1()
+	assert.NoError(t, err)
+	diff, err = cur.DiffByPath(*legacy)
+	assert.NoError(t, err)
+	assert.False(t, diff.Equal())
+	report = diff.String()
+	assert.Contains(t, report, "modified:   c.txt")
+	assert.NotContains(t, report, "mode changed")
+	assert.NotContains(t, report, "link")
+	assert.NotContains(t, report, "empty")
+}
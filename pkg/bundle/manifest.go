@@ -23,7 +23,10 @@ import (
 
 const (
 	// ManifestSchemaVersion is the current manifest schema version.
-	ManifestSchemaVersion = 1
+	ManifestSchemaVersion = 2
+
+	// ManifestSchemaVersionLegacy is the previous manifest schema version, still supported.
+	ManifestSchemaVersionLegacy = 1
 
 	// ManifestFilename is the default filename for manifest when stored.
 	ManifestFilename = ".vcn.manifest.json"
@@ -44,6 +47,17 @@ const (
 //  - json representation of the manifest MUST NOT be indented
 //  - sha256 is the only digest's algorithm that MUST be used
 //
+// Specifications (version 2), as per version 1 plus:
+//  - `schemaVersion` MUST be always 2
+//  - `items.mode` MUST be one of "100644" (regular file), "100755" (executable file),
+//    "120000" (symbolic link) or "040000" (empty directory)
+//  - `items.target` MUST be set for symbolic links only, and their digest MUST be the digest of the target
+//  - empty directories MUST have a zero size and the digest of the empty content
+//  - `items` MUST be sorted by its digest's value, then by its mode's value (lexically byte-wise)
+//  - multiple `items` MUST NOT have the same digest and mode values
+//
+// Version 1 manifests, which record regular files only, MUST NOT have `items.mode` nor `items.target`.
+//
 // The Normalize() method provides sorting funcionality and specification enforcement. It's implictly called
 // when the manifest is marshalled.
 type Manifest struct {
@@ -67,20 +81,23 @@ func (m *Manifest) MarshalJSON() ([]byte, error) {
 
 // Normalize deduplicates and sorts items and items's paths in accordance with manifest's schema specs.
 // An error is returned when duplicate paths across different items are found, or if digest's algo
-// does not match sha256.
+// does not match sha256, or when items do not comply with the manifest's schema version (either 1 or 2).
 func (m *Manifest) Normalize() error {
 	if m == nil {
 		return fmt.Errorf("nil manifest")
 	}
 
-	if m.SchemaVersion != ManifestSchemaVersion {
+	if m.SchemaVersion != ManifestSchemaVersion && m.SchemaVersion != ManifestSchemaVersionLegacy {
 		return fmt.Errorf("unsupported bundle.Manifest schema version: %d", m.SchemaVersion)
 	}
 
 	// make unique index
 	idx := make(map[string]Descriptor, len(m.Items))
 	for _, d := range m.Items {
-		k := d.Digest.String()
+		if err := m.checkMode(&d); err != nil {
+			return err
+		}
+		k := d.Digest.String() + " " + d.Mode
 		if dd, ok := idx[k]; ok {
 			if d.Size != dd.Size {
 				return fmt.Errorf(
@@ -120,13 +137,80 @@ func (m *Manifest) Normalize() error {
 		}
 	}
 
-	// finally, sort items by digest
+	// finally, sort items by digest, then by mode
 	sort.SliceStable(m.Items, func(k, j int) bool {
-		return m.Items[k].Digest.String() < m.Items[j].Digest.String()
+		if dk, dj := m.Items[k].Digest.String(), m.Items[j].Digest.String(); dk != dj {
+			return dk < dj
+		}
+		return m.Items[k].Mode < m.Items[j].Mode
 	})
 	return nil
 }
 
+// checkMode enforces mode and target specs of d in accordance with m's schema version.
+// For version 2, a missing mode defaults to ModeFile.
+func (m *Manifest) checkMode(d *Descriptor) error {
+	if m.SchemaVersion == ManifestSchemaVersionLegacy {
+		if d.Mode != "" || d.Target != "" {
+			return fmt.Errorf("mode and target are not supported by bundle.Manifest schema version %d", m.SchemaVersion)
+		}
+		return nil
+	}
+
+	if d.Mode == "" {
+		d.Mode = ModeFile
+	}
+	if !modes[d.Mode] {
+		return fmt.Errorf("unsupported mode (%s) for digest %s", d.Mode, d.Digest.String())
+	}
+	switch d.Mode {
+	case ModeSymlink:
+		if d.Digest != ManifestDigestAlgo.FromString(d.Target) {
+			return fmt.Errorf("symbolic link target does not match digest (%s): %s", d.Digest.String(), d.Target)
+		}
+	case ModeDir:
+		if d.Size != 0 || d.Digest != ManifestDigestAlgo.FromBytes(nil) {
+			return fmt.Errorf("directory entries must be empty: %s", d.Digest.String())
+		}
+		fallthrough
+	default:
+		if d.Target != "" {
+			return fmt.Errorf("target is allowed for symbolic links only: %s", d.Digest.String())
+		}
+	}
+	return nil
+}
+
+// V1 returns a copy of m converted to the legacy schema version, i.e. containing regular files only
+// without their modes. It's useful to compare m against manifests produced by older versions.
+// If m already follows the legacy schema version, it's returned as is.
+func (m *Manifest) V1() (*Manifest, error) {
+	if err := m.Normalize(); err != nil {
+		return nil, err
+	}
+	if m.SchemaVersion == ManifestSchemaVersionLegacy {
+		return m, nil
+	}
+
+	items := make([]Descriptor, 0, len(m.Items))
+	for _, d := range m.Items {
+		if d.Mode != ModeFile && d.Mode != ModeExecutable {
+			continue
+		}
+		d.Paths = append([]string{}, d.Paths...)
+		d.Mode = ""
+		items = append(items, d)
+	}
+	v1 := &Manifest{
+		SchemaVersion: ManifestSchemaVersionLegacy,
+		Items:         items,
+	}
+	if err := v1.Normalize(); err != nil {
+		return nil, err
+	}
+	return v1, nil
+}
+
 // Digest digests the JSON encoded m and returns a digest.Digest.
 func (m *Manifest) Digest() (digest.Digest, error) {
 	b, err := json.Marshal(m) // sorting is implicitly called by Marshal
@@ -137,7 +221,7 @@ func (m *Manifest) Digest() (digest.Digest, error) {
 	return digest.SHA256.FromBytes(b), nil
 }
 
-// NewManifest returns a new empty Manifest.
+// NewManifest returns a new Manifest, following the current schema version, containing items.
 func NewManifest(items ...Descriptor) *Manifest {
 	if items == nil {
 		items = make([]Descriptor, 0)
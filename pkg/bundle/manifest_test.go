@@ -44,11 +44,23 @@ func TestManifest(t *testing.T) {
 
 	assert.NotNil(t, m)
 
+	j, err := json.Marshal(m)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		`{"schemaVersion":2,"items":[{"digest":"sha256:bef57ec7f53a6d40beb640a780a639c83bc29ac8a9816f1fc6c5c6dcd93c4721","size":6,"paths":["letters.txt"],"mode":"100644"},{"digest":"sha256:c775e7b757ede630cd0aa1113bd102661ab38829ca52a6422ab782862f268646","size":10,"paths":["digits.txt","dup-digits.txt"],"mode":"100644"}]}`,
+		string(j),
+	)
+
+	// legacy version
+	m, err = m.V1()
+	assert.NoError(t, err)
+
 	d, err := m.Digest()
 	assert.NoError(t, err)
 	assert.Equal(t, "sha256:2cc48ce16beff9987ad31f32a7623bda48317d9232dbb75ba9a83f6d85ed073e", d.String())
 
-	j, err := json.Marshal(m)
+	j, err = json.Marshal(m)
 	assert.NoError(t, err)
 	assert.Equal(
 		t,
@@ -58,6 +70,61 @@ func TestManifest(t *testing.T) {
 
 }
 
+func TestManifestModes(t *testing.T) {
+	m := getTestManifest(t)
+	exe, err := NewDescriptor("run.sh", strings.NewReader("abcdef"))
+	assert.NoError(t, err)
+	exe.Mode = ModeExecutable
+	m.Items = append(
+		m.Items,
+		*exe,
+		*NewSymlinkDescriptor("link", "abcdef"),
+		*NewDirDescriptor("empty"),
+	)
+
+	assert.NoError(t, m.Normalize())
+	assert.Len(t, m.Items, 5)
+
+	// same content, distinct modes
+	assert.Equal(t, m.Items[0].Digest, m.Items[1].Digest)
+	assert.Equal(t, m.Items[0].Digest, m.Items[2].Digest)
+	assert.Equal(t, []string{"letters.txt"}, m.Items[0].Paths)
+	assert.Equal(t, ModeFile, m.Items[0].Mode)
+	assert.Equal(t, []string{"run.sh"}, m.Items[1].Paths)
+	assert.Equal(t, ModeExecutable, m.Items[1].Mode)
+	assert.Equal(t, []string{"link"}, m.Items[2].Paths)
+	assert.Equal(t, ModeSymlink, m.Items[2].Mode)
+	assert.Equal(t, "abcdef", m.Items[2].Target)
+	assert.Equal(t, []string{"empty"}, m.Items[4].Paths)
+	assert.Equal(t, ModeDir, m.Items[4].Mode)
+
+	// legacy version keeps regular files only
+	v1, err := m.V1()
+	assert.NoError(t, err)
+	assert.Equal(t, uint(ManifestSchemaVersionLegacy), v1.SchemaVersion)
+	assert.Len(t, v1.Items, 2)
+	assert.Equal(t, []string{"letters.txt", "run.sh"}, v1.Items[0].Paths)
+	assert.Empty(t, v1.Items[0].Mode)
+
+	// modes are not allowed by the legacy version
+	v1.Items[0].Mode = ModeFile
+	assert.Error(t, v1.Normalize())
+
+	// symlink's digest must match its target
+	m.Items[2].Target = "other"
+	assert.Error(t, m.Normalize())
+	m.Items[2].Target = "abcdef"
+
+	// target is allowed for symlinks only
+	m.Items[1].Target = "abcdef"
+	assert.Error(t, m.Normalize())
+	m.Items[1].Target = ""
+
+	// unknown mode
+	m.Items[1].Mode = "100600"
+	assert.Error(t, m.Normalize())
+}
+
 func TestWriteReadManifest(t *testing.T) {
 	tmpfile, err := ioutil.TempFile("", "vcn-manifest")
 	if err != nil {
@@ -0,0 +1,251 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package bundle
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	digest "github.com/opencontainers/go-digest"
+)
+
+// Merkle tree nodes' prefixes, as per RFC 6962 (second preimage resistance).
+const (
+	leafPrefix = 0x00
+	nodePrefix = 0x01
+)
+
+// Proof is an inclusion proof of a single item into the Merkle root of a manifest.
+//
+// Leaves of the tree are the manifest's items, one per path and sorted by path,
+// each one hashed as the JSON encoding of its Descriptor having that path only.
+// The tree is built as per RFC 6962.
+type Proof struct {
+	// Item is the descriptor of the proven item, having a single path.
+	Item Descriptor `json:"item"`
+
+	// Index is the position of the item's leaf within the tree.
+	Index uint64 `json:"index"`
+
+	// Size is the number of leaves of the tree.
+	Size uint64 `json:"size"`
+
+	// Hashes is the audit path, from the leaf to the root.
+	Hashes []digest.Digest `json:"hashes"`
+
+	// Root is the expected Merkle root.
+	Root digest.Digest `json:"root"`
+}
+
+// leaves returns the items of m, one per path and sorted by path.
+func (m *Manifest) leaves() ([]Descriptor, error) {
+	if err := m.Normalize(); err != nil {
+		return nil, err
+	}
+
+	leaves := make([]Descriptor, 0, len(m.Items))
+	for _, d := range m.Items {
+		for _, p := range d.Paths {
+			l := d
+			l.Paths = []string{p}
+			leaves = append(leaves, l)
+		}
+	}
+	sort.Slice(leaves, func(i, j int) bool {
+		return leaves[i].Paths[0] < leaves[j].Paths[0]
+	})
+	return leaves, nil
+}
+
+func leafHash(d Descriptor) ([]byte, error) {
+	b, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	h := ManifestDigestAlgo.Hash()
+	h.Write([]byte{leafPrefix})
+	h.Write(b)
+	return h.Sum(nil), nil
+}
+
+func nodeHash(left, right []byte) []byte {
+	h := ManifestDigestAlgo.Hash()
+	h.Write([]byte{nodePrefix})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// split returns the largest power of two smaller than n (n must be greater than 1).
+func split(n int) int {
+	k := 1
+	for k<<1 < n {
+		k <<= 1
+	}
+	return k
+}
+
+func treeHash(hashes [][]byte) []byte {
+	switch len(hashes) {
+	case 0:
+		return ManifestDigestAlgo.Hash().Sum(nil)
+	case 1:
+		return hashes[0]
+	}
+	k := split(len(hashes))
+	return nodeHash(treeHash(hashes[:k]), treeHash(hashes[k:]))
+}
+
+// auditPath returns the hashes needed to compute the root of hashes from the leaf at index i.
+func auditPath(i int, hashes [][]byte) [][]byte {
+	if len(hashes) <= 1 {
+		return nil
+	}
+	k := split(len(hashes))
+	if i < k {
+		return append(auditPath(i, hashes[:k]), treeHash(hashes[k:]))
+	}
+	return append(auditPath(i-k, hashes[k:]), treeHash(hashes[:k]))
+}
+
+func leafHashes(leaves []Descriptor) ([][]byte, error) {
+	hashes := make([][]byte, len(leaves))
+	for i, l := range leaves {
+		h, err := leafHash(l)
+		if err != nil {
+			return nil, err
+		}
+		hashes[i] = h
+	}
+	return hashes, nil
+}
+
+// MerkleRoot returns the root of the Merkle tree built over m's items, as an alternative to Digest()
+// allowing to prove that a single item belongs to m without disclosing the whole manifest (see Proof()).
+func (m *Manifest) MerkleRoot() (digest.Digest, error) {
+	leaves, err := m.leaves()
+	if err != nil {
+		return "", err
+	}
+	hashes, err := leafHashes(leaves)
+	if err != nil {
+		return "", err
+	}
+	return digest.NewDigestFromBytes(ManifestDigestAlgo, treeHash(hashes)), nil
+}
+
+// Proof returns the inclusion proof of the item at path into m's Merkle root.
+func (m *Manifest) Proof(path string) (*Proof, error) {
+	leaves, err := m.leaves()
+	if err != nil {
+		return nil, err
+	}
+	i := sort.Search(len(leaves), func(i int) bool {
+		return leaves[i].Paths[0] >= path
+	})
+	if i == len(leaves) || leaves[i].Paths[0] != path {
+		return nil, fmt.Errorf("no item found in manifest for path: %s", path)
+	}
+
+	hashes, err := leafHashes(leaves)
+	if err != nil {
+		return nil, err
+	}
+	ap := auditPath(i, hashes)
+	p := &Proof{
+		Item:   leaves[i],
+		Index:  uint64(i),
+		Size:   uint64(len(leaves)),
+		Hashes: make([]digest.Digest, len(ap)),
+		Root:   digest.NewDigestFromBytes(ManifestDigestAlgo, treeHash(hashes)),
+	}
+	for j, h := range ap {
+		p.Hashes[j] = digest.NewDigestFromBytes(ManifestDigestAlgo, h)
+	}
+	return p, nil
+}
+
+// Verify computes the Merkle root from p's item and audit path, then returns it if it matches p.Root.
+// An error is returned when the proof is malformed or does not match.
+func (p *Proof) Verify() (digest.Digest, error) {
+	if p == nil {
+		return "", fmt.Errorf("nil proof")
+	}
+	if len(p.Item.Paths) != 1 {
+		return "", fmt.Errorf("proof item must have exactly one path")
+	}
+	if p.Index >= p.Size {
+		return "", fmt.Errorf("proof index (%d) out of range (%d)", p.Index, p.Size)
+	}
+
+	// normalize the item as per a single item manifest
+	m := NewManifest(p.Item)
+	if p.Item.Mode == "" {
+		m.SchemaVersion = ManifestSchemaVersionLegacy
+	}
+	if err := m.Normalize(); err != nil {
+		return "", err
+	}
+
+	r, err := leafHash(m.Items[0])
+	if err != nil {
+		return "", err
+	}
+
+	// see RFC 9162, section 2.1.3.2
+	fn, sn := p.Index, p.Size-1
+	for _, d := range p.Hashes {
+		if sn == 0 {
+			return "", fmt.Errorf("proof audit path is too long")
+		}
+		if d.Algorithm() != ManifestDigestAlgo || d.Validate() != nil {
+			return "", fmt.Errorf("invalid digest in proof audit path: %s", d)
+		}
+		h, err := hex.DecodeString(d.Encoded())
+		if err != nil {
+			return "", err
+		}
+		if fn&1 == 1 || fn == sn {
+			r = nodeHash(h, r)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = nodeHash(r, h)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if sn != 0 {
+		return "", fmt.Errorf("proof audit path is too short")
+	}
+
+	root := digest.NewDigestFromBytes(ManifestDigestAlgo, r)
+	if root != p.Root {
+		return "", fmt.Errorf("proof does not match root %s", p.Root)
+	}
+	return root, nil
+}
+
+// ReadProof reads the file named by filename and returns the decoded proof.
+func ReadProof(filename string) (*Proof, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	p := Proof{}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("invalid proof: %s", err)
+	}
+	return &p, nil
+}
@@ -0,0 +1,82 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package bundle
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerkleProof(t *testing.T) {
+	for n := 1; n <= 17; n++ {
+		items := make([]Descriptor, n)
+		for i := range items {
+			// some items share the same content
+			d, err := NewDescriptor(fmt.Sprintf("file-%02d", i), strings.NewReader(fmt.Sprint(i%3)))
+			assert.NoError(t, err)
+			items[i] = *d
+		}
+		m := NewManifest(items...)
+		root, err := m.MerkleRoot()
+		assert.NoError(t, err)
+		d, err := m.Digest()
+		assert.NoError(t, err)
+		assert.NotEqual(t, d, root)
+
+		for i := range items {
+			p, err := m.Proof(fmt.Sprintf("file-%02d", i))
+			assert.NoError(t, err)
+			assert.Equal(t, uint64(i), p.Index)
+			assert.Equal(t, uint64(n), p.Size)
+			got, err := p.Verify()
+			assert.NoError(t, err, "size: %d, index: %d", n, i)
+			assert.Equal(t, root, got)
+		}
+	}
+
+	m := getTestManifest(t)
+	_, err := m.Proof("not-existing")
+	assert.Error(t, err)
+
+	p, err := m.Proof("letters.txt")
+	assert.NoError(t, err)
+	_, err = p.Verify()
+	assert.NoError(t, err)
+
+	// tampered item
+	tampered := *p
+	tampered.Item.Mode = ModeExecutable
+	_, err = tampered.Verify()
+	assert.Error(t, err)
+
+	// wrong index
+	tampered = *p
+	tampered.Index = 0
+	_, err = tampered.Verify()
+	assert.Error(t, err)
+
+	// truncated audit path
+	tampered = *p
+	tampered.Hashes = nil
+	_, err = tampered.Verify()
+	assert.Error(t, err)
+
+	// legacy manifests are supported as well
+	v1, err := m.V1()
+	assert.NoError(t, err)
+	p, err = v1.Proof("digits.txt")
+	assert.NoError(t, err)
+	root, err := p.Verify()
+	assert.NoError(t, err)
+	expected, _ := v1.MerkleRoot()
+	assert.Equal(t, expected, root)
+}
@@ -0,0 +1,123 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package bundle
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/vchain-us/vcn/pkg/bundle"
+)
+
+// NewCommand returns the cobra command for `vcn bundle`
+func NewCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "Work with manifests of notarized directories",
+		Long: `
+Work with manifests of notarized directories.
+
+A manifest (.vcn.manifest.json) is written within each directory
+after a successful notarization.
+`,
+		Args: cobra.NoArgs,
+	}
+
+	proof := &cobra.Command{
+		Use:     "proof <path>",
+		Example: "  vcn bundle proof ./release/bin/vcn > vcn.proof.json",
+		Short:   "Print the inclusion proof of a single file of a notarized directory",
+		Long: `
+Print the inclusion proof of a single file of a notarized directory.
+
+The proof allows to authenticate the file alone (see vcn authenticate --proof)
+against the Merkle root of the directory's manifest, without the need of
+shipping the whole manifest. The directory must have been notarized by
+using vcn notarize --merkle.
+
+The manifest is looked up within the file's parent directories,
+unless --manifest is used.
+`,
+		RunE: runProof,
+		Args: cobra.ExactArgs(1),
+	}
+	proof.Flags().String("manifest", "", "read the manifest from the given file, paths are relative to the file's directory")
+	cmd.AddCommand(proof)
+
+	return cmd
+}
+
+func runProof(cmd *cobra.Command, args []string) error {
+	output, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return err
+	}
+	if output != "" && output != "json" {
+		return fmt.Errorf("output format not supported: %s", output)
+	}
+
+	manifestFile, err := cmd.Flags().GetString("manifest")
+	if err != nil {
+		return err
+	}
+
+	cmd.SilenceUsage = true
+
+	filename, err := filepath.Abs(args[0])
+	if err != nil {
+		return err
+	}
+	if manifestFile == "" {
+		if manifestFile, err = findManifest(filename); err != nil {
+			return err
+		}
+	}
+	manifestFile, err = filepath.Abs(manifestFile)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := bundle.ReadManifest(manifestFile)
+	if err != nil {
+		return fmt.Errorf("cannot read manifest %s: %s", manifestFile, err)
+	}
+
+	path, err := filepath.Rel(filepath.Dir(manifestFile), filename)
+	if err != nil {
+		return err
+	}
+	proof, err := manifest.Proof(filepath.ToSlash(path))
+	if err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(proof, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(b))
+	return nil
+}
+
+// findManifest returns the filename of the manifest within the closest parent directory of filename.
+func findManifest(filename string) (string, error) {
+	for dir := filepath.Dir(filename); ; dir = filepath.Dir(dir) {
+		m := filepath.Join(dir, bundle.ManifestFilename)
+		if _, err := os.Stat(m); err == nil {
+			return m, nil
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			break
+		}
+	}
+	return "", fmt.Errorf("no %s found for %s", bundle.ManifestFilename, filename)
+}
@@ -0,0 +1,181 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vchain-us/vcn/pkg/api"
+	"github.com/vchain-us/vcn/pkg/store"
+	"gopkg.in/yaml.v2"
+)
+
+type entry struct {
+	Hash         string                      `json:"hash" yaml:"hash"`
+	Signers      []string                    `json:"signers,omitempty" yaml:"signers,omitempty"`
+	Verification *api.BlockchainVerification `json:"verification" yaml:"verification"`
+	CreatedAt    time.Time                   `json:"createdAt" yaml:"createdAt"`
+	ExpiresAt    time.Time                   `json:"expiresAt" yaml:"expiresAt"`
+	Expired      bool                        `json:"expired" yaml:"expired"`
+}
+
+// NewCommand returns the cobra command for `vcn cache`
+func NewCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the local verification cache",
+		Long: `
+Manage the local verification cache.
+
+Authentication results are cached within the vcn directory, keyed by
+hash and SignerID(s), for the time set by "vcn authenticate --cache-ttl".
+`,
+		Args: cobra.NoArgs,
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List cached verifications",
+		RunE:    runList,
+		Args:    cobra.NoArgs,
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "prune",
+		Short: "Remove expired cached verifications",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRemove(cmd, store.PruneCache)
+		},
+		Args: cobra.NoArgs,
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "clear",
+		Short: "Remove all cached verifications",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRemove(cmd, store.ClearCache)
+		},
+		Args: cobra.NoArgs,
+	})
+
+	return cmd
+}
+
+func runList(cmd *cobra.Command, args []string) error {
+	output, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+
+	cached, err := store.CacheEntries()
+	if err != nil {
+		return err
+	}
+
+	entries := make([]entry, len(cached))
+	for i, e := range cached {
+		v := &api.BlockchainVerification{}
+		if err := json.Unmarshal(e.Value, v); err != nil {
+			v = nil
+		}
+		entries[i] = entry{
+			Hash:         e.Hash,
+			Signers:      e.Signers,
+			Verification: v,
+			CreatedAt:    e.CreatedAt,
+			ExpiresAt:    e.ExpiresAt,
+			Expired:      e.Expired(),
+		}
+	}
+
+	switch output {
+	case "":
+		if len(entries) == 0 {
+			fmt.Println("No cached verifications.")
+			return nil
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(w, "HASH\tSTATUS\tSIGNERS\tEXPIRES")
+		for _, e := range entries {
+			status := "-"
+			if e.Verification != nil {
+				status = e.Verification.Status.String()
+			}
+			signers := "-"
+			if len(e.Signers) > 0 {
+				signers = strings.Join(e.Signers, ",")
+			}
+			expires := e.ExpiresAt.Local().Format(time.RFC3339)
+			if e.Expired {
+				expires = "expired"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.Hash, status, signers, expires)
+		}
+		return w.Flush()
+	case "yaml":
+		b, err := yaml.Marshal(entries)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+	case "json":
+		b, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+	default:
+		return fmt.Errorf("output format not supported: %s", output)
+	}
+	return nil
+}
+
+func runRemove(cmd *cobra.Command, remove func() (int, error)) error {
+	output, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+
+	n, err := remove()
+	if err != nil {
+		return err
+	}
+	removed := struct {
+		Removed int `json:"removed" yaml:"removed"`
+	}{n}
+
+	switch output {
+	case "":
+		fmt.Printf("%d cached verifications removed.\n", n)
+	case "yaml":
+		b, err := yaml.Marshal(removed)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+	case "json":
+		b, err := json.MarshalIndent(removed, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+	default:
+		return fmt.Errorf("output format not supported: %s", output)
+	}
+	return nil
+}
@@ -15,7 +15,10 @@ import (
 
 	"golang.org/x/crypto/ssh/terminal"
 
+	"github.com/vchain-us/vcn/pkg/cmd/bundle"
+	"github.com/vchain-us/vcn/pkg/cmd/cache"
 	"github.com/vchain-us/vcn/pkg/cmd/dashboard"
+	"github.com/vchain-us/vcn/pkg/cmd/diff"
 	"github.com/vchain-us/vcn/pkg/cmd/info"
 	"github.com/vchain-us/vcn/pkg/cmd/inspect"
 	"github.com/vchain-us/vcn/pkg/cmd/internal/cli"
@@ -87,6 +90,8 @@ func init() {
 	rootCmd.PersistentFlags().StringP("output", "o", "", "output format, one of: --output=json|--output=yaml|--output=''")
 	rootCmd.PersistentFlags().BoolP("quit", "q", true, "if false, ask for confirmation before quitting")
 	rootCmd.PersistentFlags().MarkHidden("quit")
+	rootCmd.PersistentFlags().Duration("timeout", 0, "maximum time allowed for platform, blockchain and registry operations (0 means no timeout)")
+	viper.BindPFlag("timeout", rootCmd.PersistentFlags().Lookup("timeout"))
 
 	// Root command flags
 	rootCmd.Flags().BoolP("version", "v", false, "version for vcn") // needed for -v shorthand
@@ -95,6 +100,9 @@ func init() {
 	rootCmd.AddCommand(verify.NewCommand())
 	rootCmd.AddCommand(inspect.NewCommand())
 	rootCmd.AddCommand(list.NewCommand())
+	rootCmd.AddCommand(cache.NewCommand())
+	rootCmd.AddCommand(bundle.NewCommand())
+	rootCmd.AddCommand(diff.NewCommand())
 
 	// Signing group
 	rootCmd.AddCommand(sign.NewCommand())
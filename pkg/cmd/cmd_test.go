@@ -0,0 +1,857 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/vchain-us/vcn/internal/sim"
+	"github.com/vchain-us/vcn/pkg/api"
+	"github.com/vchain-us/vcn/pkg/cmd/internal/types"
+	"github.com/vchain-us/vcn/pkg/extractor/dir"
+	"github.com/vchain-us/vcn/pkg/meta"
+	"github.com/vchain-us/vcn/pkg/store"
+	"github.com/vchain-us/vcn/pkg/uri"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+	"golang.org/x/crypto/openpgp/packet"
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// execute runs the root command with args and returns what has been written to stdout.
+func execute(t *testing.T, args ...string) (string, error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() {
+		os.Stdout = stdout
+	}()
+
+	done := make(chan []byte)
+	go func() {
+		b, _ := ioutil.ReadAll(r)
+		done <- b
+	}()
+
+	rootCmd.SetArgs(args)
+	cmd, err := rootCmd.ExecuteC()
+	w.Close()
+
+	// flags keep their values across executions, so reset them
+	reset := func(f *pflag.Flag) {
+		if f.Changed {
+			if f.Value.Type() == "stringSlice" {
+				// slices would append to their previous values, so replace them
+				fs := pflag.NewFlagSet(f.Name, pflag.ContinueOnError)
+				fs.StringSlice(f.Name, nil, "")
+				f.Value = fs.Lookup(f.Name).Value
+			} else {
+				f.Value.Set(f.DefValue)
+			}
+			f.Changed = false
+		}
+	}
+	cmd.Flags().VisitAll(reset)
+	rootCmd.PersistentFlags().VisitAll(reset)
+
+	return string(<-done), err
+}
+
+// setup starts a simulated environment with a logged in user,
+// and returns a temporary working directory and the user's SignerID.
+func setup(t *testing.T) (tdir string, signerID common.Address, teardown func()) {
+	_, tdir, signerID, teardown = setupSim(t)
+	return
+}
+
+// setupSim is like setup, but it returns the simulated environment too.
+func setupSim(t *testing.T) (s *sim.Sim, tdir string, signerID common.Address, teardown func()) {
+	s, err := sim.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tdir, err = ioutil.TempDir("", "vcn-testing")
+	if err != nil {
+		s.Close()
+		t.Fatal(err)
+	}
+	store.SetDir(tdir)
+
+	email := "alice@example.com"
+	signerID, err = s.AddUser(email, "password", "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv(meta.VcnUserEnv, email)
+	os.Setenv(meta.VcnPasswordEnv, "password")
+	os.Setenv(meta.VcnNotarizationPassword, "passphrase")
+
+	teardown = func() {
+		os.Unsetenv(meta.VcnUserEnv)
+		os.Unsetenv(meta.VcnPasswordEnv)
+		os.Unsetenv(meta.VcnNotarizationPassword)
+		os.RemoveAll(tdir)
+		s.Close()
+	}
+
+	if _, err = execute(t, "login", "-o", "json"); err != nil {
+		teardown()
+		t.Fatal(err)
+	}
+	return
+}
+
+func TestNotarizeAuthenticateInspect(t *testing.T) {
+	tdir, signerID, teardown := setup(t)
+	defer teardown()
+
+	asset := filepath.Join(tdir, "asset.txt")
+	if err := ioutil.WriteFile(asset, []byte("hello vcn"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := execute(t, "notarize", asset, "-o", "json")
+	assert.NoError(t, err)
+	notarized := types.Result{}
+	assert.NoError(t, json.Unmarshal([]byte(out), &notarized))
+	assert.Equal(t, "asset.txt", notarized.Name)
+	assert.True(t, notarized.Verification.Trusted())
+	assert.Equal(t, signerID, notarized.Verification.Owner)
+	assert.Equal(t, meta.LevelEmailVerified, notarized.Verification.Level)
+
+	out, err = execute(t, "authenticate", asset, "-o", "json")
+	assert.NoError(t, err)
+	authenticated := types.Result{}
+	assert.NoError(t, json.Unmarshal([]byte(out), &authenticated))
+	assert.Equal(t, notarized.Hash, authenticated.Hash)
+	assert.True(t, authenticated.Verification.Trusted())
+
+	_, err = execute(t, "authenticate", asset, "--receipt", tdir, "-o", "json")
+	assert.NoError(t, err)
+	receipt := filepath.Join(tdir, notarized.Hash+".receipt.json")
+	if r, err := api.LoadReceipt(receipt); assert.NoError(t, err) {
+		assert.NotEmpty(t, r.TxHash)
+		assert.NotZero(t, r.BlockNumber)
+	}
+	_, err = execute(t, "authenticate", asset, "--from-receipt", receipt, "--receipt-signer", signerID.Hex(), "--online", "-o", "json")
+	assert.NoError(t, err)
+
+	_, err = execute(t, "untrust", asset, "-o", "json")
+	assert.NoError(t, err)
+
+	// offline, the receipt must be signed by a trusted receipt signer
+	_, err = execute(t, "authenticate", asset, "--from-receipt", receipt, "-o", "json")
+	assert.Error(t, err)
+	_, err = execute(t, "authenticate", asset, "--from-receipt", receipt, "--receipt-signer", "0x0000000000000000000000000000000000000001", "-o", "json")
+	assert.Error(t, err)
+
+	// the receipt still holds offline, but not online
+	_, err = execute(t, "authenticate", asset, "--from-receipt", receipt, "--receipt-signer", signerID.Hex(), "-o", "json")
+	assert.NoError(t, err)
+	_, err = execute(t, "authenticate", asset, "--from-receipt", receipt, "--receipt-signer", signerID.Hex(), "--online", "-o", "json")
+	assert.Error(t, err)
+
+	// online too, since the receipt's owner is the one checked against the blockchain
+	_, err = execute(t, "authenticate", asset, "--from-receipt", receipt, "--online", "-o", "json")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "--receipt-signer")
+	}
+
+	_, err = execute(t, "authenticate", asset, "-o", "json")
+	if assert.Error(t, err) {
+		assert.True(t, strings.HasSuffix(err.Error(), "is untrusted"))
+	}
+
+	out, err = execute(t, "inspect", "--hash", notarized.Hash, "-o", "json")
+	assert.NoError(t, err)
+	history := []types.Result{}
+	assert.NoError(t, json.Unmarshal([]byte(out), &history))
+	if assert.Len(t, history, 2) {
+		assert.Equal(t, meta.StatusTrusted, history[0].Verification.Status)
+		assert.Equal(t, meta.StatusUntrusted, history[1].Verification.Status)
+		for _, r := range history {
+			assert.Empty(t, r.Errors)
+			assert.Equal(t, signerID, r.Verification.Owner)
+		}
+	}
+}
+
+func TestNotarizeBatch(t *testing.T) {
+	tdir, signerID, teardown := setup(t)
+	defer teardown()
+
+	for _, name := range []string{"a.txt", "b.txt", "c.bin"} {
+		if err := ioutil.WriteFile(filepath.Join(tdir, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hash := "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	list := filepath.Join(tdir, "assets.list")
+	content := "# assets\n\n" + filepath.Join(tdir, "c.bin") + "\n" + hash + "\n"
+	if err := ioutil.WriteFile(list, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := execute(t, "notarize", filepath.Join(tdir, "*.txt"), "--from-file", list, "-o", "json")
+	assert.NoError(t, err)
+	results := []types.Result{}
+	assert.NoError(t, json.Unmarshal([]byte(out), &results))
+	if assert.Len(t, results, 4) {
+		assert.Equal(t, "a.txt", results[0].Name)
+		assert.Equal(t, "b.txt", results[1].Name)
+		assert.Equal(t, "c.bin", results[2].Name)
+		assert.Equal(t, hash, results[3].Hash)
+		for _, r := range results {
+			assert.Empty(t, r.Errors)
+			assert.True(t, r.Verification.Trusted())
+			assert.Equal(t, signerID, r.Verification.Owner)
+		}
+	}
+
+	_, err = execute(t, "authenticate", "--hash", hash, "-o", "json")
+	assert.NoError(t, err)
+
+	_, err = execute(t, "notarize", filepath.Join(tdir, "*.none"), "-o", "json")
+	assert.Error(t, err)
+}
+
+// broadcastErrLedger fails the n-th Sign call after the transaction has been mined.
+type broadcastErrLedger struct {
+	api.Ledger
+	n     int
+	calls int
+}
+
+func (l *broadcastErrLedger) Sign(ctx context.Context, transactor *bind.TransactOpts, hash string, status meta.Status) (common.Hash, error) {
+	l.calls++
+	tx, err := l.Ledger.Sign(ctx, transactor, hash, status)
+	if err == nil && l.calls == l.n {
+		return common.Hash{}, fmt.Errorf("connection lost")
+	}
+	return tx, err
+}
+
+func TestNotarizeBatchBroadcastError(t *testing.T) {
+	s, tdir, signerID, teardown := setupSim(t)
+	defer teardown()
+	api.SetLedger(&broadcastErrLedger{Ledger: s.Ledger, n: 2})
+
+	args := []string{"notarize", "-o", "json"}
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		name = filepath.Join(tdir, name)
+		if err := ioutil.WriteFile(name, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		args = append(args, name)
+	}
+
+	out, err := execute(t, args...)
+	assert.Error(t, err)
+	results := []struct {
+		Name         string                      `json:"name"`
+		Errors       []interface{}               `json:"error"`
+		Verification *api.BlockchainVerification `json:"verification"`
+	}{}
+	assert.NoError(t, json.Unmarshal([]byte(out), &results))
+	if assert.Len(t, results, 3) {
+		assert.NotEmpty(t, results[1].Errors)
+		// the following notarization must not reuse the nonce of the failed one
+		for _, i := range []int{0, 2} {
+			assert.Empty(t, results[i].Errors, results[i].Name)
+			if assert.NotNil(t, results[i].Verification, results[i].Name) {
+				assert.True(t, results[i].Verification.Trusted(), results[i].Name)
+				assert.Equal(t, signerID, results[i].Verification.Owner, results[i].Name)
+			}
+		}
+	}
+}
+
+func TestAuthenticateParallel(t *testing.T) {
+	tdir, _, teardown := setup(t)
+	defer teardown()
+
+	args := []string{"authenticate", "--parallel", "4", "-o", "json"}
+	for i := 0; i < 10; i++ {
+		name := filepath.Join(tdir, fmt.Sprintf("%02d.txt", i))
+		if err := ioutil.WriteFile(name, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		args = append(args, name)
+	}
+
+	_, err := execute(t, "notarize", filepath.Join(tdir, "*.txt"), "-o", "json")
+	assert.NoError(t, err)
+	_, err = execute(t, "untrust", filepath.Join(tdir, "03.txt"), "-o", "json")
+	assert.NoError(t, err)
+
+	out, err := execute(t, args...)
+	if assert.Error(t, err) {
+		assert.True(t, strings.HasSuffix(err.Error(), "is untrusted"))
+	}
+
+	dec := json.NewDecoder(strings.NewReader(out))
+	for i := 0; i < 10; i++ {
+		r := types.Result{}
+		if assert.NoError(t, dec.Decode(&r)) {
+			assert.Equal(t, fmt.Sprintf("%02d.txt", i), r.Name)
+			assert.Equal(t, i != 3, r.Verification.Trusted())
+		}
+	}
+	assert.False(t, dec.More())
+}
+
+func TestAuthenticateCache(t *testing.T) {
+	tdir, _, teardown := setup(t)
+	defer teardown()
+
+	asset := filepath.Join(tdir, "asset.txt")
+	if err := ioutil.WriteFile(asset, []byte("hello cache"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cached := func() []map[string]interface{} {
+		out, err := execute(t, "cache", "list", "-o", "json")
+		assert.NoError(t, err)
+		entries := []map[string]interface{}{}
+		assert.NoError(t, json.Unmarshal([]byte(out), &entries))
+		return entries
+	}
+
+	_, err := execute(t, "notarize", asset, "-o", "json")
+	assert.NoError(t, err)
+
+	// the cache is disabled by default
+	_, err = execute(t, "authenticate", asset, "-o", "json")
+	assert.NoError(t, err)
+	assert.Empty(t, cached())
+
+	_, err = execute(t, "authenticate", asset, "--cache-ttl", "5m", "-o", "json")
+	assert.NoError(t, err)
+	assert.Len(t, cached(), 1)
+
+	out, err := execute(t, "cache", "clear", "-o", "json")
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"removed":1}`, out)
+	_, err = execute(t, "authenticate", asset, "--cache-ttl", "5m", "--no-cache", "-o", "json")
+	assert.NoError(t, err)
+	assert.Empty(t, cached())
+
+	// notarizing invalidates the cache
+	_, err = execute(t, "authenticate", asset, "--cache-ttl", "5m", "-o", "json")
+	assert.NoError(t, err)
+	_, err = execute(t, "untrust", asset, "-o", "json")
+	assert.NoError(t, err)
+	_, err = execute(t, "authenticate", asset, "--cache-ttl", "5m", "-o", "json")
+	assert.Error(t, err)
+
+	entries := cached()
+	if assert.Len(t, entries, 1) {
+		created, _ := time.Parse(time.RFC3339Nano, entries[0]["createdAt"].(string))
+		expires, _ := time.Parse(time.RFC3339Nano, entries[0]["expiresAt"].(string))
+		assert.True(t, expires.Sub(created) <= api.UntrustedCacheTTL)
+	}
+}
+
+func TestAuthenticateGitSignature(t *testing.T) {
+	tdir, _, teardown := setup(t)
+	defer teardown()
+
+	keyRing := func(name string) (*openpgp.Entity, string) {
+		e, err := openpgp.NewEntity(name, "", name+"@example.com", &packet.Config{RSABits: 1024})
+		if err != nil {
+			t.Fatal(err)
+		}
+		filename := filepath.Join(tdir, name+".asc")
+		f, err := os.Create(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		w, _ := armor.Encode(f, openpgp.PublicKeyType, nil)
+		defer w.Close()
+		if err := e.Serialize(w); err != nil {
+			t.Fatal(err)
+		}
+		return e, filename
+	}
+	alice, aliceKeyRing := keyRing("alice")
+	_, bobKeyRing := keyRing("bob")
+
+	repoDir := filepath.Join(tdir, "repo")
+	repo, err := git.PlainInit(repoDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(repoDir, "file.txt"), []byte("hello vcn"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	wt, _ := repo.Worktree()
+	wt.Add("file.txt")
+	_, err = wt.Commit("signed", &git.CommitOptions{
+		Author:  &object.Signature{Name: "alice", Email: "alice@example.com", When: time.Now()},
+		SignKey: alice,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = execute(t, "notarize", "git://"+repoDir, "-o", "json")
+	assert.NoError(t, err)
+
+	_, err = execute(t, "authenticate", "git://"+repoDir, "--git-keyring", aliceKeyRing, "-o", "json")
+	assert.NoError(t, err)
+
+	_, err = execute(t, "authenticate", "git://"+repoDir, "--git-keyring", bobKeyRing, "-o", "json")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "has not a valid signature")
+	}
+
+	_, err = execute(t, "authenticate", filepath.Join(repoDir, "file.txt"), "--git-keyring", aliceKeyRing, "-o", "json")
+	assert.Error(t, err)
+}
+
+func TestNotarizeAuthenticateGitRange(t *testing.T) {
+	tdir, _, teardown := setup(t)
+	defer teardown()
+
+	repoDir := filepath.Join(tdir, "repo")
+	repo, err := git.PlainInit(repoDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt, _ := repo.Worktree()
+	commits := make([]string, 4)
+	for i := range commits {
+		name := fmt.Sprintf("%d.txt", i)
+		if err := ioutil.WriteFile(filepath.Join(repoDir, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		wt.Add(name)
+		h, err := wt.Commit(name, &git.CommitOptions{
+			Author: &object.Signature{Name: "alice", Email: "alice@example.com", When: time.Now()},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		commits[i] = h.String()
+	}
+
+	out, err := execute(t, "notarize", "git://"+repoDir+"@"+commits[0]+".."+commits[2], "-o", "json")
+	assert.NoError(t, err)
+	results := []types.Result{}
+	assert.NoError(t, json.Unmarshal([]byte(out), &results))
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, "repo@"+commits[1][:7], results[0].Name)
+		assert.Equal(t, "repo@"+commits[2][:7], results[1].Name)
+	}
+
+	_, err = execute(t, "authenticate", "git://"+repoDir+"@"+commits[0]+".."+commits[2], "-o", "json")
+	assert.NoError(t, err)
+
+	_, err = execute(t, "untrust", "git://"+repoDir+"@"+commits[2], "-o", "json")
+	assert.NoError(t, err)
+
+	// commits[1] is trusted, commits[2] is untrusted, commits[3] was not notarized
+	out, err = execute(t, "authenticate", "git://"+repoDir+"@"+commits[0]+"..", "-o", "json")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "2 of 3 assets could not be authenticated")
+		assert.Contains(t, err.Error(), "repo@"+commits[2][:7]+": ")
+		assert.Contains(t, err.Error(), "is untrusted")
+		assert.Contains(t, err.Error(), "repo@"+commits[3][:7]+": ")
+		assert.Contains(t, err.Error(), "was not notarized")
+	}
+	dec := json.NewDecoder(strings.NewReader(out))
+	for i := 1; i < 4; i++ {
+		r := types.Result{}
+		if assert.NoError(t, dec.Decode(&r)) {
+			assert.Equal(t, "repo@"+commits[i][:7], r.Name)
+			assert.Equal(t, i == 1, r.Verification.Trusted())
+		}
+	}
+}
+
+func TestAuthenticateLegacyManifest(t *testing.T) {
+	tdir, _, teardown := setup(t)
+	defer teardown()
+
+	asset := filepath.Join(tdir, "asset")
+	if err := os.MkdirAll(filepath.Join(asset, "empty"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(asset, "run.sh"), []byte("echo vcn"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// notarized by a former version, as per the legacy manifest schema
+	u, _ := uri.Parse("dir://" + asset)
+	a, err := dir.Artifact(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest, _ := dir.Metadata(*a)
+	legacy, err := manifest.V1()
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, _ := legacy.Digest()
+	assert.NotEqual(t, a.Hash, d.Encoded())
+	_, err = execute(t, "notarize", "--hash", d.Encoded(), "--name", "asset", "-o", "json")
+	assert.NoError(t, err)
+
+	for _, args := range [][]string{
+		{"authenticate", "dir://" + asset, "-o", "json"},
+		{"authenticate", "dir://" + asset, "--parallel", "2", "-o", "json"},
+	} {
+		out, err := execute(t, args...)
+		assert.NoError(t, err)
+		r := types.Result{}
+		assert.NoError(t, json.Unmarshal([]byte(out), &r))
+		assert.Equal(t, d.Encoded(), r.Hash)
+		assert.True(t, r.Verification.Trusted())
+	}
+}
+
+func TestAuthenticateProof(t *testing.T) {
+	tdir, _, teardown := setup(t)
+	defer teardown()
+
+	asset := filepath.Join(tdir, "asset")
+	if err := os.MkdirAll(filepath.Join(asset, "bin"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	target := filepath.Join(asset, "bin", "run.sh")
+	if err := ioutil.WriteFile(target, []byte("echo vcn"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(asset, "README"), []byte("vcn"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := execute(t, "notarize", "dir://"+asset, "--merkle", "-o", "json")
+	assert.NoError(t, err)
+	notarized := types.Result{}
+	assert.NoError(t, json.Unmarshal([]byte(out), &notarized))
+
+	out, err = execute(t, "bundle", "proof", target)
+	assert.NoError(t, err)
+	proof := filepath.Join(tdir, "run.sh.proof.json")
+	if err := ioutil.WriteFile(proof, []byte(out), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the file alone
+	copied := filepath.Join(tdir, "run.sh")
+	if err := ioutil.WriteFile(copied, []byte("echo vcn"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	out, err = execute(t, "authenticate", "--proof", proof, copied, "-o", "json")
+	assert.NoError(t, err)
+	authenticated := types.Result{}
+	assert.NoError(t, json.Unmarshal([]byte(out), &authenticated))
+	assert.Equal(t, notarized.Hash, authenticated.Hash)
+	assert.True(t, authenticated.Verification.Trusted())
+
+	// mode matters
+	os.Chmod(copied, 0644)
+	_, err = execute(t, "authenticate", "--proof", proof, copied, "-o", "json")
+	assert.Error(t, err)
+
+	// content matters
+	_, err = execute(t, "authenticate", "--proof", proof, filepath.Join(asset, "README"), "-o", "json")
+	assert.Error(t, err)
+
+	// not a file of the notarized directory
+	_, err = execute(t, "bundle", "proof", copied)
+	assert.Error(t, err)
+}
+
+func TestAuthenticateDiff(t *testing.T) {
+	tdir, _, teardown := setup(t)
+	defer teardown()
+
+	asset := filepath.Join(tdir, "asset")
+	if err := os.MkdirAll(asset, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{"a.txt": "a", "b.txt": "b", "c.txt": "c"} {
+		if err := ioutil.WriteFile(filepath.Join(asset, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := execute(t, "notarize", "dir://"+asset, "-o", "json")
+	assert.NoError(t, err)
+
+	// no diff
+	out, err := execute(t, "authenticate", "dir://"+asset, "-o", "json")
+	assert.NoError(t, err)
+	assert.NotContains(t, out, `"diff"`)
+
+	ioutil.WriteFile(filepath.Join(asset, "a.txt"), []byte("changed"), 0644)
+	os.Rename(filepath.Join(asset, "b.txt"), filepath.Join(asset, "renamed.txt"))
+	os.Remove(filepath.Join(asset, "c.txt"))
+	ioutil.WriteFile(filepath.Join(asset, "d.txt"), []byte("d"), 0644)
+
+	for _, output := range []string{"json", "yaml"} {
+		out, err = execute(t, "authenticate", "dir://"+asset, "-o", output)
+		assert.Error(t, err)
+		assert.Contains(t, out, "modified")
+	}
+
+	out, _ = execute(t, "authenticate", "dir://"+asset, "-o", "json")
+	r := types.Result{}
+	assert.NoError(t, json.Unmarshal([]byte(out), &r))
+	if assert.NotNil(t, r.Diff) {
+		if assert.Len(t, r.Diff.Modified, 1) {
+			assert.Equal(t, "a.txt", r.Diff.Modified[0].To.Path)
+			assert.Equal(t, uint64(7), r.Diff.Modified[0].To.Size)
+		}
+		if assert.Len(t, r.Diff.Renamed, 1) {
+			assert.Equal(t, "b.txt", r.Diff.Renamed[0].From.Path)
+			assert.Equal(t, "renamed.txt", r.Diff.Renamed[0].To.Path)
+		}
+		if assert.Len(t, r.Diff.Deleted, 1) {
+			assert.Equal(t, "c.txt", r.Diff.Deleted[0].From.Path)
+		}
+		if assert.Len(t, r.Diff.Added, 1) {
+			assert.Equal(t, "d.txt", r.Diff.Added[0].To.Path)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tdir, _, teardown := setup(t)
+	defer teardown()
+
+	v1 := filepath.Join(tdir, "v1")
+	v2 := filepath.Join(tdir, "v2")
+	for _, d := range []string{v1, v2} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+		for name, content := range map[string]string{"a.txt": "a", "b.txt": "b"} {
+			if err := ioutil.WriteFile(filepath.Join(d, name), []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// equal
+	out, err := execute(t, "diff", "dir://"+v1, v2, "-o", "json")
+	assert.NoError(t, err)
+	r := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal([]byte(out), &r))
+	assert.Equal(t, true, r["equal"])
+	assert.Nil(t, r["diff"])
+
+	out, err = execute(t, "notarize", "dir://"+v1, "-o", "json")
+	assert.NoError(t, err)
+	notarized := types.Result{}
+	assert.NoError(t, json.Unmarshal([]byte(out), &notarized))
+
+	ioutil.WriteFile(filepath.Join(v2, "b.txt"), []byte("changed"), 0644)
+
+	// by hash, by manifest file and by directory
+	for _, from := range []string{notarized.Hash, filepath.Join(v1, ".vcn.manifest.json"), "dir://" + v1} {
+		out, err = execute(t, "diff", from, "dir://"+v2, "-o", "json")
+		assert.Error(t, err)
+		r := map[string]interface{}{}
+		assert.NoError(t, json.Unmarshal([]byte(out), &r), from)
+		assert.Equal(t, false, r["equal"], from)
+		assert.Contains(t, out, `"modified"`, from)
+		assert.Contains(t, out, `"b.txt"`, from)
+	}
+
+	// text output
+	out, err = execute(t, "diff", notarized.Hash, "dir://"+v2)
+	assert.Error(t, err)
+	assert.Contains(t, out, "modified:   b.txt")
+
+	// unknown hash
+	_, err = execute(t, "diff", strings.Repeat("0", 64), "dir://"+v2)
+	assert.Error(t, err)
+
+	// not a bundle
+	_, err = execute(t, "diff", "dir://"+v1, filepath.Join(v1, "a.txt"))
+	assert.Error(t, err)
+}
+
+func TestAuthenticatePolicy(t *testing.T) {
+	tdir, signerID, teardown := setup(t)
+	defer teardown()
+
+	asset := filepath.Join(tdir, "asset.txt")
+	if err := ioutil.WriteFile(asset, []byte("hello policy"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := execute(t, "notarize", asset, "-o", "json")
+	assert.NoError(t, err)
+
+	writePolicy := func(data string) string {
+		filename := filepath.Join(tdir, "policy.yaml")
+		if err := ioutil.WriteFile(filename, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return filename
+	}
+
+	// satisfied
+	pol := writePolicy(fmt.Sprintf("minLevel: 1\nmaxAge: 1h\nallow:\n  - kinds: [file]\n    signers: [%s]\n", signerID.Hex()))
+	out, err := execute(t, "authenticate", asset, "--policy", pol, "-o", "json")
+	assert.NoError(t, err)
+	r := types.Result{}
+	assert.NoError(t, json.Unmarshal([]byte(out), &r))
+	if assert.NotNil(t, r.Policy) {
+		assert.True(t, r.Policy.Passed)
+		assert.Len(t, r.Policy.Rules, 4)
+	}
+
+	// not satisfied, by env var
+	pol = writePolicy("minLevel: 3\nallow:\n  - kinds: [docker]\n    signers: [" + signerID.Hex() + "]\n")
+	os.Setenv("VCN_POLICY", pol)
+	defer os.Unsetenv("VCN_POLICY")
+	out, err = execute(t, "authenticate", asset, "-o", "json")
+	assert.Error(t, err)
+	r = types.Result{}
+	assert.NoError(t, json.Unmarshal([]byte(out), &r))
+	assert.True(t, r.Verification.Trusted())
+	if assert.NotNil(t, r.Policy) {
+		assert.False(t, r.Policy.Passed)
+		failed := r.Policy.Failed()
+		if assert.Len(t, failed, 2) {
+			assert.Equal(t, "minLevel", failed[0].Rule)
+			assert.Equal(t, "allow", failed[1].Rule)
+		}
+	}
+
+	_, err = execute(t, "authenticate", asset)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "does not satisfy the policy: minLevel")
+	}
+}
+
+func TestAuthenticateQuorum(t *testing.T) {
+	s, tdir, alice, teardown := setupSim(t)
+	defer teardown()
+
+	bob, err := s.AddUser("bob@example.com", "password", "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	carol, err := s.AddUser("carol@example.com", "password", "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	signerIDs := strings.Join([]string{alice.Hex(), bob.Hex(), carol.Hex()}, ",")
+
+	asset := filepath.Join(tdir, "asset.txt")
+	if err := ioutil.WriteFile(asset, []byte("hello quorum"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	login := func(email string) {
+		os.Setenv(meta.VcnUserEnv, email)
+		if _, err := execute(t, "login", "-o", "json"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	quorum := func(n string) (*api.Quorum, error) {
+		out, err := execute(t, "authenticate", asset, "--signerID", signerIDs, "--quorum", n, "-o", "json")
+		r := types.Result{}
+		assert.NoError(t, json.Unmarshal([]byte(out), &r))
+		return r.Quorum, err
+	}
+
+	_, err = execute(t, "notarize", asset, "-o", "json")
+	assert.NoError(t, err)
+
+	q, err := quorum("2")
+	assert.Error(t, err)
+	if assert.NotNil(t, q) {
+		assert.Equal(t, uint(1), q.Trusted)
+		assert.Equal(t, uint(2), q.Required)
+	}
+
+	login("bob@example.com")
+	_, err = execute(t, "notarize", asset, "-o", "json")
+	assert.NoError(t, err)
+
+	q, err = quorum("2")
+	assert.NoError(t, err)
+	assert.True(t, q.Reached())
+
+	_, err = quorum("3")
+	assert.Error(t, err)
+
+	// only the most recent status of each signer counts
+	_, err = execute(t, "untrust", asset, "-o", "json")
+	assert.NoError(t, err)
+	q, err = quorum("2")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "is trusted by 1 of 2 required signers")
+	}
+	assert.Len(t, q.Signers, 2)
+
+	_, err = execute(t, "authenticate", asset, "--quorum", "2")
+	assert.Error(t, err)
+}
+
+func TestAuthenticateMinLevel(t *testing.T) {
+	tdir, _, teardown := setup(t)
+	defer teardown()
+
+	asset := filepath.Join(tdir, "asset.txt")
+	if err := ioutil.WriteFile(asset, []byte("hello level"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := execute(t, "notarize", asset, "-o", "json")
+	assert.NoError(t, err)
+
+	for _, level := range []string{"1", "email_verified"} {
+		out, err := execute(t, "authenticate", asset, "--min-level", level, "-o", "json")
+		assert.NoError(t, err)
+		assert.NotContains(t, out, "rejection")
+	}
+
+	out, err := execute(t, "authenticate", asset, "--min-level", "ID_VERIFIED", "-o", "json")
+	assert.Error(t, err)
+	r := types.Result{}
+	assert.NoError(t, json.Unmarshal([]byte(out), &r))
+	assert.True(t, r.Verification.Trusted())
+	if assert.NotNil(t, r.Rejection) {
+		assert.Equal(t, types.RejectionLevelTooLow, r.Rejection.Reason)
+		assert.Equal(t, meta.LevelEmailVerified, r.Rejection.Level)
+		assert.Equal(t, meta.LevelIDVerified, r.Rejection.MinLevel)
+	}
+
+	_, err = execute(t, "authenticate", asset, "--min-level", "3")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "is trusted, but its level (1 - EMAIL_VERIFIED) is lower than the required one (3 - ID_VERIFIED)")
+	}
+
+	_, err = execute(t, "authenticate", asset, "--min-level", "5")
+	assert.Error(t, err)
+}
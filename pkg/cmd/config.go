@@ -13,10 +13,13 @@ import (
 	"os"
 
 	"github.com/vchain-us/vcn/pkg/extractor"
+	"github.com/vchain-us/vcn/pkg/extractor/archive"
 	"github.com/vchain-us/vcn/pkg/extractor/dir"
 	"github.com/vchain-us/vcn/pkg/extractor/docker"
 	"github.com/vchain-us/vcn/pkg/extractor/file"
 	"github.com/vchain-us/vcn/pkg/extractor/git"
+	"github.com/vchain-us/vcn/pkg/extractor/oci"
+	"github.com/vchain-us/vcn/pkg/extractor/registry"
 
 	"github.com/vchain-us/vcn/pkg/store"
 )
@@ -30,7 +33,16 @@ func initConfig() {
 	extractor.Register(dir.Scheme, dir.Artifact)
 	extractor.Register(docker.Scheme, docker.Artifact)
 	extractor.Register(docker.SchemePodman, docker.Artifact)
+	extractor.Register(oci.Scheme, oci.Artifact)
+	extractor.Register(registry.Scheme, registry.Artifact)
 	extractor.Register(git.Scheme, git.Artifact)
+	extractor.Register(git.SchemeTree, git.Artifact)
+	extractor.Register(archive.SchemeTar, archive.Artifact)
+	extractor.Register(archive.SchemeZip, archive.Artifact)
+
+	// Register extractors for URIs referring to multiple artifacts
+	extractor.RegisterMulti(git.Scheme, git.Artifacts)
+	extractor.RegisterMulti(git.SchemeTree, git.Artifacts)
 
 	// Load config
 	if cfgFile != "" {
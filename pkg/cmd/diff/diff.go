@@ -0,0 +1,215 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"github.com/vchain-us/vcn/pkg/bundle"
+	"github.com/vchain-us/vcn/pkg/extractor"
+	"github.com/vchain-us/vcn/pkg/extractor/archive"
+	"github.com/vchain-us/vcn/pkg/extractor/dir"
+	"github.com/vchain-us/vcn/pkg/store"
+)
+
+var hashRegExp = regexp.MustCompile("^[0-9a-f]{64}$")
+
+type result struct {
+	From  string           `json:"from" yaml:"from"`
+	To    string           `json:"to" yaml:"to"`
+	Equal bool             `json:"equal" yaml:"equal"`
+	Diff  *bundle.PathDiff `json:"diff,omitempty" yaml:"diff,omitempty"`
+}
+
+// NewCommand returns the cobra command for `vcn diff`
+func NewCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "diff <A> <B>",
+		Example: "  vcn diff dir://release-1.0 dir://release-1.1",
+		Short:   "Compare two directories, archives or manifests",
+		Long: `
+Compare two directories, archives or manifests.
+
+Changes of B relative to A are reported by path (new, modified, renamed and
+deleted items, mode changes and retargeted links). No authentication is
+performed.
+
+A and B must be one of:
+  dir://<directory>
+  tar://<archive>
+  zip://<archive>
+  <manifest file>, e.g. .vcn.manifest.json
+  <hash>, of a directory or an archive notarized by using this machine
+
+The exit code will be 0 only if A and B are equal. Otherwise, the exit code
+will be 1.
+`,
+		RunE: runDiff,
+		Args: cobra.ExactArgs(2),
+	}
+
+	cmd.Flags().Bool("extra-ignore-files", false, "when processing directories, honour .gitignore and .dockerignore files too")
+	cmd.Flags().Bool("raw", false, "print the raw diff of manifests, instead of the diff by path (text output only)")
+
+	return cmd
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	output, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return err
+	}
+
+	raw, err := cmd.Flags().GetBool("raw")
+	if err != nil {
+		return err
+	}
+	if raw && output != "" {
+		return fmt.Errorf("cannot use --raw with --output")
+	}
+
+	options := []extractor.Option{}
+	extraIgnoreFiles, err := cmd.Flags().GetBool("extra-ignore-files")
+	if err != nil {
+		return err
+	}
+	if extraIgnoreFiles {
+		options = append(options, dir.WithExtraIgnoreFiles())
+	}
+
+	cmd.SilenceUsage = true
+
+	from, err := loadManifest(args[0], options...)
+	if err != nil {
+		return err
+	}
+	to, err := loadManifest(args[1], options...)
+	if err != nil {
+		return err
+	}
+
+	diff, err := to.DiffByPath(*from)
+	if err != nil {
+		return err
+	}
+	r := result{
+		From:  args[0],
+		To:    args[1],
+		Equal: diff.Equal(),
+	}
+	if !r.Equal {
+		r.Diff = diff
+	}
+
+	switch output {
+	case "":
+		report := diff.String()
+		if raw {
+			if report, r.Equal, err = to.Diff(*from); err != nil {
+				return err
+			}
+		}
+		if r.Equal {
+			fmt.Println("No differences found.")
+		} else {
+			fmt.Printf("Diff from %s to %s\n\n%s\n", args[0], args[1], report)
+		}
+	case "yaml":
+		b, err := yaml.Marshal(r)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+	case "json":
+		b, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+	default:
+		return fmt.Errorf("output format not supported: %s", output)
+	}
+
+	if !r.Equal {
+		if output != "" {
+			cmd.SilenceErrors = true
+		}
+		return fmt.Errorf("%s and %s differ", args[0], args[1])
+	}
+	return nil
+}
+
+// loadManifest returns the manifest referenced by arg.
+func loadManifest(arg string, options ...extractor.Option) (*bundle.Manifest, error) {
+	// a notarized hash
+	if hash := strings.ToLower(arg); hashRegExp.MatchString(hash) {
+		if _, err := os.Stat(arg); os.IsNotExist(err) {
+			return storedManifest(hash)
+		}
+	}
+
+	// a manifest file
+	if info, err := os.Stat(arg); err == nil && info.Mode().IsRegular() {
+		m, err := bundle.ReadManifest(arg)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read manifest %s: %s", arg, err)
+		}
+		return m, nil
+	}
+
+	// a directory without scheme
+	if info, err := os.Stat(arg); err == nil && info.IsDir() {
+		arg = dir.Scheme + "://" + arg
+	}
+
+	a, err := extractor.Extract(arg, options...)
+	if err != nil {
+		return nil, err
+	}
+	if a != nil {
+		if m, _ := dir.Metadata(*a); m != nil {
+			return m, nil
+		}
+		if m, _ := archive.Metadata(*a); m != nil {
+			return m, nil
+		}
+	}
+	return nil, fmt.Errorf("%s is not a directory, an archive, a manifest nor a notarized hash", arg)
+}
+
+// storedManifest returns the locally stored manifest notarized by hash.
+func storedManifest(hash string) (*bundle.Manifest, error) {
+	m, err := bundle.ReadManifest(store.ManifestFile(hash))
+	if err != nil {
+		return nil, fmt.Errorf("no manifest found for %s, it can be retrieved only when notarized by using this machine", hash)
+	}
+
+	// the hash may be either the manifest's digest or its Merkle root
+	d, err := m.Digest()
+	if err != nil {
+		return nil, err
+	}
+	if d.Encoded() != hash {
+		root, err := m.MerkleRoot()
+		if err != nil {
+			return nil, err
+		}
+		if root.Encoded() != hash {
+			return nil, fmt.Errorf("manifest stored for %s does not match its hash", hash)
+		}
+	}
+	return m, nil
+}
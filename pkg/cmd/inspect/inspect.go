@@ -110,7 +110,10 @@ func extractInfo(arg string, output string) (hash string, err error) {
 }
 
 func inspect(hash string, u *api.User, output string) error {
-	verifications, err := api.BlockChainInspect(hash)
+	ctx, cancel := cli.Context()
+	defer cancel()
+
+	verifications, err := api.BlockChainInspectContext(ctx, hash)
 	if err != nil {
 		return err
 	}
@@ -126,7 +129,7 @@ func inspect(hash string, u *api.User, output string) error {
 
 	results := make([]types.Result, l)
 	for i, v := range verifications {
-		ar, err := api.LoadArtifact(u, hash, v.MetaHash())
+		ar, err := api.LoadArtifactContext(ctx, u, hash, v.MetaHash())
 		results[i] = *types.NewResult(nil, ar, &v)
 		if err != nil {
 			results[i].AddError(err)
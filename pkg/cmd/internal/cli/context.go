@@ -0,0 +1,29 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package cli
+
+import (
+	"context"
+
+	"github.com/spf13/viper"
+)
+
+// Context returns a context honoring the global timeout setting (--timeout or VCN_TIMEOUT),
+// and its cancel function that must be called as soon as the operation is done.
+func Context() (context.Context, context.CancelFunc) {
+	return WithTimeout(context.Background())
+}
+
+// WithTimeout is like Context, but derives the returned context from parent.
+func WithTimeout(parent context.Context) (context.Context, context.CancelFunc) {
+	if timeout := viper.GetDuration("timeout"); timeout > 0 {
+		return context.WithTimeout(parent, timeout)
+	}
+	return context.WithCancel(parent)
+}
@@ -109,6 +109,48 @@ func WriteResultTo(r *types.Result, out io.Writer) (n int64, err error) {
 		}
 	}
 
+	if rej := r.Rejection; rej != nil {
+		c, s := meta.StyleError()
+		err = printf("Rejected:\t%s\n", color.New(c, s).Sprintf(rej.Message))
+		if err != nil {
+			return
+		}
+	}
+
+	if q := r.Quorum; q != nil {
+		c, s := meta.StyleSuccess()
+		if !q.Reached() {
+			c, s = meta.StyleError()
+		}
+		err = printf("Quorum:\t%s\n", color.New(c, s).Sprintf("%d of %d required signers", q.Trusted, q.Required))
+		if err != nil {
+			return
+		}
+	}
+
+	if p := r.Policy; p != nil {
+		c, s := meta.StyleSuccess()
+		value := "PASSED"
+		if !p.Passed {
+			c, s = meta.StyleError()
+			value = "FAILED"
+		}
+		err = printf("Policy:\t%s\n", color.New(c, s).Sprintf(value))
+		if err != nil {
+			return
+		}
+		for _, rr := range p.Rules {
+			value = "pass"
+			if !rr.Passed {
+				value = "fail, " + rr.Reason
+			}
+			err = printf("\t%s (%s)\n", rr.Rule, value)
+			if err != nil {
+				return
+			}
+		}
+	}
+
 	for _, e := range r.Errors {
 		c, s := meta.StyleError()
 		err = printf("Error:\t%s\n", color.New(c, s).Sprintf(e.Error()))
@@ -0,0 +1,84 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package cli
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/caarlos0/spin"
+)
+
+// Progress is a spinner (see spin.Spinner) also showing how many items were processed so far.
+// It's shown on the first call to Update(), and hidden when all items have been processed or by Stop().
+type Progress struct {
+	text    string
+	frames  []rune
+	done    int64
+	total   int64
+	mu      sync.Mutex
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// NewProgress returns a new *Progress printing text, that must contain a verb for the spinner frame
+// followed by two verbs for the number of items processed so far and the total number of items
+// (eg. "%s Processing... %d/%d").
+func NewProgress(text string) *Progress {
+	return &Progress{
+		text:   spin.ClearLine + text,
+		frames: []rune(spin.Spin1),
+	}
+}
+
+// Update sets the number of items processed so far and the total, and shows p if not yet shown.
+// When done equals total, p is hidden.
+func (p *Progress) Update(done, total int) {
+	atomic.StoreInt64(&p.done, int64(done))
+	atomic.StoreInt64(&p.total, int64(total))
+
+	if done >= total {
+		p.Stop()
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stop == nil {
+		p.stop = make(chan struct{})
+		p.stopped = make(chan struct{})
+		go p.run(p.stop, p.stopped)
+	}
+}
+
+func (p *Progress) run(stop <-chan struct{}, stopped chan<- struct{}) {
+	defer close(stopped)
+	for i := 0; ; i++ {
+		fmt.Printf(p.text, string(p.frames[i%len(p.frames)]), atomic.LoadInt64(&p.done), atomic.LoadInt64(&p.total))
+		select {
+		case <-stop:
+			fmt.Printf(spin.ClearLine)
+			return
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// Stop hides p, if shown.
+func (p *Progress) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stop != nil {
+		close(p.stop)
+		<-p.stopped
+		p.stop = nil
+	}
+}
@@ -0,0 +1,47 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package types
+
+import (
+	"fmt"
+
+	"github.com/vchain-us/vcn/pkg/api"
+	"github.com/vchain-us/vcn/pkg/meta"
+)
+
+// Rejection reasons
+const (
+	RejectionLevelTooLow = "LEVEL_TOO_LOW"
+)
+
+// Rejection describes why a TRUSTED authentication has been rejected anyway.
+type Rejection struct {
+	Reason   string     `json:"reason" yaml:"reason"`
+	Message  string     `json:"message" yaml:"message"`
+	Level    meta.Level `json:"level" yaml:"level"`
+	MinLevel meta.Level `json:"minLevel" yaml:"minLevel"`
+}
+
+// Error returns the rejection message.
+func (r *Rejection) Error() string {
+	return r.Message
+}
+
+// NewLevelRejection returns a *Rejection if v is TRUSTED but its level is lower than minLevel, otherwise nil.
+func NewLevelRejection(hash string, v *api.BlockchainVerification, minLevel meta.Level) *Rejection {
+	if !v.Trusted() || v.Level >= minLevel {
+		return nil
+	}
+	return &Rejection{
+		Reason:   RejectionLevelTooLow,
+		Message:  fmt.Sprintf("%s is trusted, but its level (%s) is lower than the required one (%s)", hash, v.Level, minLevel),
+		Level:    v.Level,
+		MinLevel: minLevel,
+	}
+}
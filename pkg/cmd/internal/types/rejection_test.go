@@ -0,0 +1,44 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vchain-us/vcn/pkg/api"
+	"github.com/vchain-us/vcn/pkg/meta"
+)
+
+func TestNewLevelRejection(t *testing.T) {
+	hash := "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+	// at the threshold
+	v := &api.BlockchainVerification{Status: meta.StatusTrusted, Level: meta.LevelIDVerified}
+	assert.Nil(t, NewLevelRejection(hash, v, meta.LevelIDVerified))
+
+	// above the threshold
+	assert.Nil(t, NewLevelRejection(hash, v, meta.LevelEmailVerified))
+
+	// below the threshold
+	r := NewLevelRejection(hash, v, meta.LevelLocationVerified)
+	if assert.NotNil(t, r) {
+		assert.Equal(t, RejectionLevelTooLow, r.Reason)
+		assert.Equal(t, meta.LevelIDVerified, r.Level)
+		assert.Equal(t, meta.LevelLocationVerified, r.MinLevel)
+		assert.Contains(t, r.Error(), hash)
+		assert.Equal(t, r.Message, r.Error())
+	}
+
+	// not TRUSTED, whatever the level
+	for _, status := range []meta.Status{meta.StatusUnknown, meta.StatusUntrusted, meta.StatusUnsupported} {
+		v := &api.BlockchainVerification{Status: status, Level: meta.LevelEmailVerified}
+		assert.Nil(t, NewLevelRejection(hash, v, meta.LevelLocationVerified), status.String())
+	}
+}
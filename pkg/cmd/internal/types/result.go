@@ -10,11 +10,17 @@ package types
 
 import (
 	"github.com/vchain-us/vcn/pkg/api"
+	"github.com/vchain-us/vcn/pkg/bundle"
+	"github.com/vchain-us/vcn/pkg/policy"
 )
 
 type Result struct {
 	api.ArtifactResponse `yaml:",inline"`
 	Verification         *api.BlockchainVerification `json:"verification" yaml:"verification"`
+	Quorum               *api.Quorum                 `json:"quorum,omitempty" yaml:"quorum,omitempty"`
+	Rejection            *Rejection                  `json:"rejection,omitempty" yaml:"rejection,omitempty"`
+	Diff                 *bundle.PathDiff            `json:"diff,omitempty" yaml:"diff,omitempty"`
+	Policy               *policy.Result              `json:"policy,omitempty" yaml:"policy,omitempty"`
 	Errors               []error                     `json:"error,omitempty" yaml:"error,omitempty"`
 }
 
@@ -34,16 +40,16 @@ func NewResult(a *api.Artifact, ar *api.ArtifactResponse, v *api.BlockchainVerif
 
 	switch true {
 	case ar != nil:
-		r = Result{*ar, vv, nil}
+		r = Result{ArtifactResponse: *ar, Verification: vv}
 	case a != nil:
-		r = Result{api.ArtifactResponse{
+		r = Result{ArtifactResponse: api.ArtifactResponse{
 			Name:        a.Name,
 			Kind:        a.Kind,
 			Hash:        a.Hash,
 			Size:        a.Size,
 			ContentType: a.ContentType,
 			Metadata:    a.Metadata,
-		}, vv, nil}
+		}, Verification: vv}
 	default:
 		r = Result{}
 		r.Verification = vv
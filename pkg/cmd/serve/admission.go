@@ -0,0 +1,261 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package serve
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/vchain-us/vcn/internal/logs"
+	"github.com/vchain-us/vcn/pkg/api"
+	"github.com/vchain-us/vcn/pkg/cmd/internal/cli"
+	"github.com/vchain-us/vcn/pkg/extractor/registry"
+	"github.com/vchain-us/vcn/pkg/meta"
+	"github.com/vchain-us/vcn/pkg/uri"
+)
+
+const (
+	admissionAPIVersion = "admission.k8s.io/v1"
+	admissionKind       = "AdmissionReview"
+)
+
+// admissionReview is the subset of the Kubernetes AdmissionReview (admission.k8s.io/v1) used by vcn.
+type admissionReview struct {
+	APIVersion string             `json:"apiVersion"`
+	Kind       string             `json:"kind"`
+	Request    *admissionRequest  `json:"request,omitempty"`
+	Response   *admissionResponse `json:"response,omitempty"`
+}
+
+type admissionRequest struct {
+	UID  string `json:"uid"`
+	Kind struct {
+		Group   string `json:"group"`
+		Version string `json:"version"`
+		Kind    string `json:"kind"`
+	} `json:"kind"`
+	Namespace string          `json:"namespace"`
+	Operation string          `json:"operation"`
+	Object    json.RawMessage `json:"object"`
+}
+
+type admissionResponse struct {
+	UID     string           `json:"uid"`
+	Allowed bool             `json:"allowed"`
+	Status  *admissionStatus `json:"status,omitempty"`
+}
+
+type admissionStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type container struct {
+	Name  string `json:"name"`
+	Image string `json:"image"`
+}
+
+type podSpec struct {
+	InitContainers      []container `json:"initContainers"`
+	Containers          []container `json:"containers"`
+	EphemeralContainers []container `json:"ephemeralContainers"`
+}
+
+type podTemplate struct {
+	Spec podSpec `json:"spec"`
+}
+
+// workload matches Pods, objects having a Pod template (e.g. Deployments, Jobs) and CronJobs.
+type workload struct {
+	Spec struct {
+		podSpec
+		Template    *podTemplate `json:"template"`
+		JobTemplate *struct {
+			Spec struct {
+				Template podTemplate `json:"template"`
+			} `json:"spec"`
+		} `json:"jobTemplate"`
+	} `json:"spec"`
+}
+
+// images returns the distinct image references within w's Pod spec, in order.
+func (w workload) images() []string {
+	spec := w.Spec.podSpec
+	switch true {
+	case w.Spec.Template != nil:
+		spec = w.Spec.Template.Spec
+	case w.Spec.JobTemplate != nil:
+		spec = w.Spec.JobTemplate.Spec.Template.Spec
+	}
+
+	images := []string{}
+	seen := map[string]bool{}
+	for _, cs := range [][]container{spec.InitContainers, spec.Containers, spec.EphemeralContainers} {
+		for _, c := range cs {
+			if c.Image != "" && !seen[c.Image] {
+				seen[c.Image] = true
+				images = append(images, c.Image)
+			}
+		}
+	}
+	return images
+}
+
+// admission authenticates the images of workloads submitted to Kubernetes,
+// acting as a ValidatingAdmissionWebhook.
+type admission struct {
+	keys          []string
+	org           string
+	exempt        map[string]bool
+	requireDigest bool
+}
+
+func newAdmission(keys []string, org string, exemptNamespaces []string, requireDigest bool) (*admission, error) {
+	if org == "" && len(keys) == 0 {
+		return nil, fmt.Errorf("--admission requires --admission-org or --admission-signerID")
+	}
+	if org != "" && len(keys) > 0 {
+		return nil, fmt.Errorf("cannot use both --admission-org and --admission-signerID")
+	}
+	a := &admission{
+		org:           org,
+		exempt:        map[string]bool{},
+		requireDigest: requireDigest,
+	}
+	for _, k := range keys {
+		if !strings.HasPrefix(k, "0x") {
+			k = "0x" + k
+		}
+		a.keys = append(a.keys, strings.ToLower(k))
+	}
+	for _, ns := range exemptNamespaces {
+		a.exempt[ns] = true
+	}
+	return a, nil
+}
+
+func (a *admission) handle(w http.ResponseWriter, r *http.Request) {
+	review := admissionReview{}
+	if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if review.Request == nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("missing request"))
+		return
+	}
+
+	ctx, cancel := cli.WithTimeout(r.Context())
+	defer cancel()
+
+	response := a.review(ctx, review.Request)
+	if !response.Allowed {
+		logs.LOG.Infof("Denied %s %s/%s: %s", review.Request.Operation, review.Request.Namespace, review.Request.Kind.Kind, response.Status.Message)
+	}
+
+	// answer with the same version of the request
+	apiVersion := review.APIVersion
+	if apiVersion == "" {
+		apiVersion = admissionAPIVersion
+	}
+	b, err := json.Marshal(admissionReview{
+		APIVersion: apiVersion,
+		Kind:       admissionKind,
+		Response:   response,
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, b)
+}
+
+// review allows req only if all its images are trusted.
+func (a *admission) review(ctx context.Context, req *admissionRequest) *admissionResponse {
+	allow := &admissionResponse{UID: req.UID, Allowed: true}
+	deny := func(format string, args ...interface{}) *admissionResponse {
+		return &admissionResponse{
+			UID: req.UID,
+			Status: &admissionStatus{
+				Code:    http.StatusForbidden,
+				Message: fmt.Sprintf(format, args...),
+			},
+		}
+	}
+
+	if a.exempt[req.Namespace] || (req.Operation != "CREATE" && req.Operation != "UPDATE") {
+		return allow
+	}
+
+	w := workload{}
+	if err := json.Unmarshal(req.Object, &w); err != nil {
+		return deny("cannot decode %s: %s", req.Kind.Kind, err)
+	}
+
+	keys := a.keys
+	if a.org != "" {
+		bo, err := api.GetBlockChainOrganisationContext(ctx, a.org)
+		if err != nil {
+			return deny("cannot resolve organisation %s: %s", a.org, err)
+		}
+		keys = bo.MembersIDs()
+	}
+
+	reasons := []string{}
+	for _, image := range w.images() {
+		if err := a.authenticate(ctx, image, keys); err != nil {
+			reasons = append(reasons, err.Error())
+		}
+	}
+	if len(reasons) > 0 {
+		return deny("%s", strings.Join(reasons, "; "))
+	}
+	return allow
+}
+
+// pinned returns true if image is referenced by digest, so that the authenticated image is the one that will run.
+func pinned(image string) bool {
+	return strings.Contains(image, "@sha256:")
+}
+
+// authenticate returns an error if image is not trusted by any of keys.
+func (a *admission) authenticate(ctx context.Context, image string, keys []string) error {
+	if a.requireDigest && !pinned(image) {
+		return fmt.Errorf("image %s is not referenced by digest (@sha256:...)", image)
+	}
+	u, err := uri.Parse(registry.Scheme + "://" + image)
+	if err != nil {
+		return fmt.Errorf("invalid image %s: %s", image, err)
+	}
+	artifact, err := registry.Artifact(u, registry.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("cannot resolve image %s: %s", image, err)
+	}
+
+	verification, err := api.VerifyMatchingSignerIDsContext(ctx, artifact.Hash, keys)
+	if err != nil {
+		return fmt.Errorf("cannot authenticate image %s: %s", image, err)
+	}
+	if !verification.Trusted() {
+		by := a.org
+		if by == "" {
+			by = strings.Join(keys, ", ")
+		}
+		label := map[meta.Status]string{
+			meta.StatusUnknown:     "was not notarized",
+			meta.StatusUntrusted:   "is untrusted",
+			meta.StatusUnsupported: "is unsupported",
+		}[verification.Status]
+		return fmt.Errorf("image %s (sha256:%s) %s by %s", image, artifact.Hash, label, by)
+	}
+	return nil
+}
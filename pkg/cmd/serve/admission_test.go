@@ -0,0 +1,141 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package serve
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vchain-us/vcn/internal/sim"
+	"github.com/vchain-us/vcn/pkg/api"
+	"github.com/vchain-us/vcn/pkg/store"
+)
+
+func TestAdmission(t *testing.T) {
+	s, err := sim.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	tdir, err := ioutil.TempDir("", "vcn-testing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tdir)
+	store.SetDir(tdir)
+	if err := store.LoadConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	r := sim.NewRegistry()
+	defer r.Close()
+	trusted := r.PushImage("vchain/trusted", "1.0", []byte(`{"architecture":"amd64","os":"linux"}`))
+	unknown := r.PushImage("vchain/unknown", "1.0", []byte(`{"architecture":"arm64","os":"linux"}`))
+
+	// notarize the trusted image
+	signerID, err := s.AddUser("alice@example.com", "password", "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := api.NewUser("alice@example.com")
+	if err := user.Authenticate("password"); err != nil {
+		t.Fatal(err)
+	}
+	keyin, _, _, err := user.Secret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = user.Sign(api.Artifact{Kind: "registry", Name: "trusted", Hash: trusted.Hex()}, api.SignWithKey(keyin, "passphrase"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = newAdmission(nil, "", nil, false)
+	assert.Error(t, err)
+	adm, err := newAdmission([]string{signerID.Hex()}, "", []string{"kube-system"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := map[string]struct {
+		allowed bool
+		message string
+	}{
+		"pod-trusted.json":          {allowed: true},
+		"pod-untrusted.json":        {message: "/vchain/unknown:1.0 (sha256:" + unknown.Hex() + ") was not notarized by " + strings.ToLower(signerID.Hex())},
+		"deployment-untrusted.json": {message: "/vchain/unknown:1.0"},
+		"cronjob-trusted.json":      {allowed: true},
+		"pod-exempt.json":           {allowed: true},
+		"pod-delete.json":           {allowed: true},
+	}
+	for name, tc := range testCases {
+		fixture, err := ioutil.ReadFile(filepath.Join("testdata", "admission", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		body := strings.Replace(string(fixture), "{{registry}}", r.Host(), -1)
+
+		in := admissionReview{}
+		if err := json.Unmarshal([]byte(body), &in); err != nil {
+			t.Fatal(err)
+		}
+
+		w := httptest.NewRecorder()
+		adm.handle(w, httptest.NewRequest(http.MethodPost, "/admission", strings.NewReader(body)))
+		assert.Equal(t, http.StatusOK, w.Code, name)
+
+		out := admissionReview{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &out), name)
+		assert.Equal(t, admissionAPIVersion, out.APIVersion, name)
+		assert.Equal(t, admissionKind, out.Kind, name)
+		if assert.NotNil(t, out.Response, name) {
+			assert.Equal(t, in.Request.UID, out.Response.UID, name)
+			assert.Equal(t, tc.allowed, out.Response.Allowed, name)
+			if !tc.allowed && assert.NotNil(t, out.Response.Status, name) {
+				assert.Equal(t, http.StatusForbidden, out.Response.Status.Code, name)
+				assert.Contains(t, out.Response.Status.Message, tc.message, name)
+			}
+		}
+	}
+
+	// images referenced by tag are denied when digests are required
+	assert.True(t, pinned(r.Host()+"/vchain/trusted@sha256:"+trusted.Hex()))
+	assert.False(t, pinned(r.Host()+"/vchain/trusted:1.0"))
+	adm, err = newAdmission([]string{signerID.Hex()}, "", []string{"kube-system"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixture, err := ioutil.ReadFile(filepath.Join("testdata", "admission", "pod-trusted.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := admissionReview{}
+	if err := json.Unmarshal([]byte(strings.Replace(string(fixture), "{{registry}}", r.Host(), -1)), &in); err != nil {
+		t.Fatal(err)
+	}
+	res := adm.review(context.Background(), in.Request)
+	assert.False(t, res.Allowed)
+	if assert.NotNil(t, res.Status) {
+		assert.Contains(t, res.Status.Message, "/vchain/trusted:1.0 is not referenced by digest")
+	}
+
+	// bad request
+	w := httptest.NewRecorder()
+	adm.handle(w, httptest.NewRequest(http.MethodPost, "/admission", strings.NewReader(`{"kind":"AdmissionReview"}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
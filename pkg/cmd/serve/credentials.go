@@ -9,12 +9,220 @@
 package serve
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
 	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
 
 	"github.com/vchain-us/vcn/pkg/api"
+	"github.com/vchain-us/vcn/pkg/cmd/internal/cli"
+	"github.com/vchain-us/vcn/pkg/meta"
+	"github.com/vchain-us/vcn/pkg/store"
+)
+
+// tokenCheckInterval is how long the stored token of an API key's user is trusted
+// before being checked against the platform again.
+const tokenCheckInterval = time.Minute
+
+var (
+	// errReadOnly is returned when a read-only API key is used to notarize.
+	errReadOnly = errors.New("read-only API key cannot be used to notarize")
+
+	// errTokenExpired is returned when the stored token of an API key's user is no longer valid.
+	errTokenExpired = errors.New("the login of the API key's user has expired, please run vcn login on the server")
 )
 
-func getCredential(r *http.Request) (user *api.User, passphrase string, err error) {
+// apiKey is a server-side configured API key, mapped to a logged in vcn user.
+type apiKey struct {
+	// Key is the secret sent by clients, as "Authorization: Bearer <key>".
+	Key string `yaml:"key"`
+
+	// User is the email of the vcn user the key acts as, the current context if empty.
+	// The user must be logged in (see vcn login), so that the stored token is used.
+	User string `yaml:"user"`
+
+	// ReadOnly keys can authenticate but cannot notarize.
+	ReadOnly bool `yaml:"readOnly"`
+
+	// PassphraseFile is the file holding the notarization password of User.
+	// If empty, VCN_NOTARIZATION_PASSWORD (or VCN_NOTARIZATION_PASSWORD_EMPTY) is used.
+	PassphraseFile string `yaml:"passphraseFile"`
+
+	passphrase string
+
+	mu         sync.Mutex
+	validUntil time.Time
+}
+
+// checkToken returns errTokenExpired if the stored token of k's user is no longer valid.
+// The platform is asked at most once per tokenCheckInterval.
+func (k *apiKey) checkToken(ctx context.Context) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if time.Now().Before(k.validUntil) {
+		return nil
+	}
+	ok, err := api.NewUser(k.User).IsAuthenticatedContext(ctx)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errTokenExpired
+	}
+	k.validUntil = time.Now().Add(tokenCheckInterval)
+	return nil
+}
+
+// expire makes k check its token again on next use.
+func (k *apiKey) expire() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.validUntil = time.Time{}
+}
+
+// apiKeys maps the SHA-256 digest of each configured key to its apiKey.
+// A nil apiKeys means that API keys are not in use, so Basic auth is accepted.
+type apiKeys map[string]*apiKey
+
+// authError returns errTokenExpired if err is due to the platform rejecting the token of user,
+// and makes all keys of user check their token again. Otherwise, err is returned.
+func (keys apiKeys) authError(user *api.User, err error) error {
+	if keys == nil || user == nil || err != api.AuthRequiredErr {
+		return err
+	}
+	for _, k := range keys {
+		if k.User == user.Email() {
+			k.expire()
+		}
+	}
+	return errTokenExpired
+}
+
+func keyDigest(key string) string {
+	d := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(d[:])
+}
+
+// loadAPIKeys reads the API keys file named by filename.
+func loadAPIKeys(filename string) (apiKeys, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	file := struct {
+		Keys []*apiKey `yaml:"keys"`
+	}{}
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid API keys file %s: %s", filename, err)
+	}
+	if len(file.Keys) == 0 {
+		return nil, fmt.Errorf("no API keys found in %s", filename)
+	}
+
+	keys := apiKeys{}
+	for i, k := range file.Keys {
+		if k.Key == "" {
+			return nil, fmt.Errorf("API key %d is empty", i)
+		}
+		if _, ok := keys[keyDigest(k.Key)]; ok {
+			return nil, fmt.Errorf("API key %d is duplicated", i)
+		}
+		if k.User == "" {
+			k.User = store.Config().CurrentContext
+		}
+		if err := checkLogin(k.User); err != nil {
+			return nil, fmt.Errorf("user of API key %d (%s): %s", i, k.User, err)
+		}
+		k.validUntil = time.Now().Add(tokenCheckInterval)
+		if !k.ReadOnly {
+			if k.passphrase, err = providePassphrase(k.PassphraseFile); err != nil {
+				return nil, fmt.Errorf("cannot read the notarization password of API key %d: %s", i, err)
+			}
+		}
+		keys[keyDigest(k.Key)] = k
+	}
+	return keys, nil
+}
+
+// checkLogin returns an error if email has no stored token, or if it is no longer valid.
+func checkLogin(email string) error {
+	stored := false
+	for _, u := range store.Config().Users {
+		if email != "" && u.Email == email && u.Token != "" {
+			stored = true
+			break
+		}
+	}
+	if !stored {
+		return fmt.Errorf("not logged in, please run vcn login")
+	}
+	ok, err := api.NewUser(email).IsAuthenticated()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("login expired, please run vcn login")
+	}
+	return nil
+}
+
+func providePassphrase(filename string) (string, error) {
+	if filename != "" {
+		data, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+	if _, empty := os.LookupEnv(meta.VcnNotarizationPasswordEmpty); empty {
+		return "", nil
+	}
+	if passphrase, ok := os.LookupEnv(meta.VcnNotarizationPassword); ok {
+		return passphrase, nil
+	}
+	return "", fmt.Errorf("no passphraseFile set, nor %s env var", meta.VcnNotarizationPassword)
+}
+
+// getCredential returns the user (and its notarization password) the request r acts as, if any.
+// When keys are in use, r must carry a valid API key and, if notarize is true, it must not be read-only.
+// Otherwise, user credentials are taken from the Basic auth of r.
+func getCredential(r *http.Request, keys apiKeys, notarize bool) (user *api.User, passphrase string, err error) {
+	if keys != nil {
+		auth := r.Header.Get("Authorization")
+		if auth == "" {
+			return
+		}
+		if !strings.HasPrefix(auth, "Bearer ") {
+			err = fmt.Errorf("only API keys are accepted, as \"Authorization: Bearer <key>\"")
+			return
+		}
+		k, ok := keys[keyDigest(strings.TrimPrefix(auth, "Bearer "))]
+		if !ok {
+			err = fmt.Errorf("invalid API key")
+			return
+		}
+		if notarize && k.ReadOnly {
+			err = errReadOnly
+			return
+		}
+		// the stored token is not refreshed, so it is checked from time to time
+		ctx, cancel := cli.WithTimeout(r.Context())
+		defer cancel()
+		if err = k.checkToken(ctx); err != nil {
+			return
+		}
+		return api.NewUser(k.User), k.passphrase, nil
+	}
+
 	if email, password, ok := r.BasicAuth(); ok {
 		user = api.NewUser(email)
 		err = user.Authenticate(password)
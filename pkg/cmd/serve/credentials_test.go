@@ -0,0 +1,174 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package serve
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/vchain-us/vcn/internal/sim"
+	"github.com/vchain-us/vcn/pkg/api"
+	"github.com/vchain-us/vcn/pkg/cmd/internal/types"
+	"github.com/vchain-us/vcn/pkg/meta"
+	"github.com/vchain-us/vcn/pkg/store"
+)
+
+func TestAPIKeys(t *testing.T) {
+	s, err := sim.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	tdir, err := ioutil.TempDir("", "vcn-testing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tdir)
+	store.SetDir(tdir)
+	if err := store.LoadConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	// alice is logged in on the server
+	signerID, err := s.AddUser("alice@example.com", "password", "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := api.NewUser("alice@example.com").Authenticate("password"); err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile := func(name, content string) string {
+		filename := filepath.Join(tdir, name)
+		if err := ioutil.WriteFile(filename, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return filename
+	}
+	passphraseFile := writeFile("passphrase", "passphrase\n")
+
+	// invalid files
+	_, err = loadAPIKeys(writeFile("empty.yaml", "keys: []"))
+	assert.Error(t, err)
+	_, err = loadAPIKeys(writeFile("unknown.yaml", "keys:\n  - key: secret\n    user: bob@example.com\n    readOnly: true\n"))
+	assert.Error(t, err)
+	_, err = loadAPIKeys(writeFile("nopassphrase.yaml", "keys:\n  - key: secret\n    user: alice@example.com\n"))
+	assert.Error(t, err)
+
+	keys, err := loadAPIKeys(writeFile("keys.yaml", `
+keys:
+  - key: write-secret
+    user: alice@example.com
+    passphraseFile: `+passphraseFile+`
+  - key: read-secret
+    user: alice@example.com
+    readOnly: true
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, keys, 2)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/notarize", func(w http.ResponseWriter, r *http.Request) {
+		sign(meta.StatusTrusted, map[string]bool{"file": true}, keys, w, r)
+	}).Methods("POST")
+	router.HandleFunc("/authenticate/{hash}", verifyHandler(keys)).Methods("GET")
+
+	hash := strings.Repeat("a", 64)
+	notarize := func(setAuth func(r *http.Request)) *httptest.ResponseRecorder {
+		body := `{"kind":"file","name":"test","hash":"` + hash + `"}`
+		r := httptest.NewRequest(http.MethodPost, "/notarize", strings.NewReader(body))
+		setAuth(r)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w
+	}
+	bearer := func(key string) func(r *http.Request) {
+		return func(r *http.Request) {
+			r.Header.Set("Authorization", "Bearer "+key)
+		}
+	}
+
+	// Basic auth is not accepted when API keys are in use
+	w := notarize(func(r *http.Request) { r.SetBasicAuth("alice@example.com", "password") })
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = notarize(bearer("wrong-secret"))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = notarize(bearer("read-secret"))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = notarize(bearer("write-secret"))
+	if assert.Equal(t, http.StatusOK, w.Code, w.Body.String()) {
+		res := types.Result{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+		if assert.NotNil(t, res.Verification) {
+			assert.Equal(t, meta.StatusTrusted, res.Verification.Status)
+			assert.Equal(t, strings.ToLower(signerID.Hex()), res.Verification.SignerID())
+		}
+	}
+
+	// read-only keys can authenticate
+	r := httptest.NewRequest(http.MethodGet, "/authenticate/"+hash, nil)
+	bearer("read-secret")(r)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	if assert.Equal(t, http.StatusOK, w.Code, w.Body.String()) {
+		res := types.Result{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+		if assert.NotNil(t, res.Verification) {
+			assert.Equal(t, meta.StatusTrusted, res.Verification.Status)
+		}
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/authenticate/"+hash, nil)
+	bearer("wrong-secret")(r)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// the stored token expires after the keys have been loaded
+	for _, u := range store.Config().Users {
+		if u.Email == "alice@example.com" {
+			u.Token = "expired"
+		}
+	}
+
+	w = notarize(bearer("write-secret"))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "vcn login")
+
+	// the token is now checked again before reaching the platform
+	w = notarize(bearer("write-secret"))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "vcn login")
+
+	r = httptest.NewRequest(http.MethodGet, "/authenticate/"+hash, nil)
+	bearer("read-secret")(r)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "vcn login")
+
+	_, err = loadAPIKeys(writeFile("expired.yaml", "keys:\n  - key: secret\n    user: alice@example.com\n    readOnly: true\n"))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "vcn login")
+	}
+}
@@ -9,6 +9,7 @@
 package serve
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 
@@ -42,6 +43,15 @@ func writeResult(w http.ResponseWriter, code int, r *types.Result) {
 	writeResponse(w, code, b)
 }
 
+// writeErrorContext is like writeError, but the response code is
+// http.StatusGatewayTimeout if the deadline of ctx has been exceeded.
+func writeErrorContext(ctx context.Context, w http.ResponseWriter, code int, err error) {
+	if ctx.Err() == context.DeadlineExceeded {
+		code = http.StatusGatewayTimeout
+	}
+	writeError(w, code, err)
+}
+
 func writeError(w http.ResponseWriter, code int, err error) {
 	eR := errorResponse{
 		Message: http.StatusText(code),
@@ -24,7 +24,47 @@ func NewCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "serve",
 		Short: "Start a local API server",
-		Long:  ``,
+		Long: `
+Start a local API server.
+
+By default, clients authenticate by Basic auth with their own
+CodeNotary credentials on each request. When --api-keys is used,
+clients must send "Authorization: Bearer <key>" instead, where each key
+is mapped to a user logged in on the server (see vcn login), so that
+the stored token is reused. Stored tokens are not refreshed: once
+one expires, requests using its keys are rejected until vcn login is
+run again on the server. Keys can be read-only (i.e. they can
+authenticate, but cannot notarize). API keys files are YAML documents,
+for example:
+
+  keys:
+    - key: <secret>
+      user: alice@example.com
+      passphraseFile: /run/secrets/alice-notarization-password
+    - key: <another secret>
+      user: alice@example.com
+      readOnly: true
+
+When passphraseFile is not set, the notarization password is read from
+VCN_NOTARIZATION_PASSWORD (or VCN_NOTARIZATION_PASSWORD_EMPTY).
+
+When --admission is used, the server acts as a Kubernetes
+ValidatingAdmissionWebhook too: AdmissionReview (admission.k8s.io/v1)
+requests posted to /admission are allowed only if all the images
+referenced by the submitted Pod spec are trusted by the signers set by
+--admission-signerID or the members of the organization set by
+--admission-org. Workloads within the namespaces set by
+--admission-exempt-namespace are always allowed.
+
+Images referenced by tag are resolved to their IDs at admission time,
+but the Pod is admitted with the original reference: if the tag is
+moved afterwards, the image that runs may differ from the one that was
+authenticated. Use --admission-require-digest to deny images that are
+not referenced by digest (i.e. image@sha256:<digest>).
+
+Images are resolved to their IDs by querying their registries
+(see registry:// and VCN_REGISTRY_USER, VCN_REGISTRY_PASSWORD).
+`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cmd.SilenceUsage = true
 			return runServe(cmd)
@@ -35,6 +75,12 @@ func NewCommand() *cobra.Command {
 	cmd.Flags().String("port", "8080", "port")
 	cmd.Flags().String("tls-cert-file", "", "TLS certificate file")
 	cmd.Flags().String("tls-key-file", "", "TLS key file")
+	cmd.Flags().String("api-keys", "", "API keys file, if set clients must authenticate by API key instead of Basic auth")
+	cmd.Flags().Bool("admission", false, "expose a Kubernetes ValidatingAdmissionWebhook endpoint at /admission")
+	cmd.Flags().StringSlice("admission-signerID", nil, "admit only images trusted by any of the passed SignerID(s)")
+	cmd.Flags().String("admission-org", "", "admit only images trusted by any member of the passed organisation's ID")
+	cmd.Flags().StringSlice("admission-exempt-namespace", nil, "always admit workloads within the passed namespace(s)")
+	cmd.Flags().Bool("admission-require-digest", false, "deny images that are not referenced by digest (image@sha256:<digest>)")
 	return cmd
 }
 
@@ -58,12 +104,35 @@ func runServe(cmd *cobra.Command) error {
 		return fmt.Errorf("--tls-cert-file is missing")
 	}
 
+	var keys apiKeys
+	if filename, _ := cmd.Flags().GetString("api-keys"); filename != "" {
+		if keys, err = loadAPIKeys(filename); err != nil {
+			return err
+		}
+		logs.LOG.Infof("API keys enabled (%d keys)", len(keys))
+	}
+
+	var adm *admission
+	if enabled, _ := cmd.Flags().GetBool("admission"); enabled {
+		keys, _ := cmd.Flags().GetStringSlice("admission-signerID")
+		org, _ := cmd.Flags().GetString("admission-org")
+		exempt, _ := cmd.Flags().GetStringSlice("admission-exempt-namespace")
+		requireDigest, _ := cmd.Flags().GetBool("admission-require-digest")
+		if adm, err = newAdmission(keys, org, exempt, requireDigest); err != nil {
+			return err
+		}
+	}
+
 	router := mux.NewRouter().StrictSlash(true)
 	router.HandleFunc("/", index)
-	router.HandleFunc("/notarize", signHander(meta.StatusTrusted)).Methods("POST")
-	router.HandleFunc("/untrust", signHander(meta.StatusUntrusted)).Methods("POST")
-	router.HandleFunc("/unsupport", signHander(meta.StatusUnsupported)).Methods("POST")
-	router.HandleFunc("/authenticate/{hash}", verify).Methods("GET")
+	router.HandleFunc("/notarize", signHander(meta.StatusTrusted, keys)).Methods("POST")
+	router.HandleFunc("/untrust", signHander(meta.StatusUntrusted, keys)).Methods("POST")
+	router.HandleFunc("/unsupport", signHander(meta.StatusUnsupported, keys)).Methods("POST")
+	router.HandleFunc("/authenticate/{hash}", verifyHandler(keys)).Methods("GET")
+	if adm != nil {
+		router.HandleFunc("/admission", adm.handle).Methods("POST")
+		logs.LOG.Infof("Admission webhook enabled at /admission")
+	}
 
 	logs.LOG.Infof("Log level: %s", logs.LOG.GetLevel().String())
 	logs.LOG.Infof("Stage: %s", meta.StageEnvironment().String())
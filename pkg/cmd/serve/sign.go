@@ -15,24 +15,29 @@ import (
 	"strings"
 
 	"github.com/vchain-us/vcn/pkg/api"
+	"github.com/vchain-us/vcn/pkg/cmd/internal/cli"
 	"github.com/vchain-us/vcn/pkg/cmd/internal/types"
 	"github.com/vchain-us/vcn/pkg/extractor"
 	"github.com/vchain-us/vcn/pkg/meta"
 )
 
-func signHander(state meta.Status) func(w http.ResponseWriter, r *http.Request) {
+func signHander(state meta.Status, keys apiKeys) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		s := state
 		k := make(map[string]bool)
 		for _, scheme := range extractor.Schemes() {
 			k[scheme] = true
 		}
-		sign(s, k, w, r)
+		sign(s, k, keys, w, r)
 	}
 }
 
-func sign(status meta.Status, kinds map[string]bool, w http.ResponseWriter, r *http.Request) {
-	user, passphrase, err := getCredential(r)
+func sign(status meta.Status, kinds map[string]bool, keys apiKeys, w http.ResponseWriter, r *http.Request) {
+	user, passphrase, err := getCredential(r, keys, true)
+	if err == errReadOnly {
+		writeError(w, http.StatusForbidden, err)
+		return
+	}
 	if err != nil {
 		writeError(w, http.StatusUnauthorized, err)
 		return
@@ -43,6 +48,10 @@ func sign(status meta.Status, kinds map[string]bool, w http.ResponseWriter, r *h
 	}
 
 	keyin, _, offline, err := user.Secret()
+	if err = keys.authError(user, err); err == errTokenExpired {
+		writeError(w, http.StatusUnauthorized, err)
+		return
+	}
 	if err != nil {
 		writeError(w, http.StatusConflict, err)
 		return
@@ -82,7 +91,11 @@ func sign(status meta.Status, kinds map[string]bool, w http.ResponseWriter, r *h
 
 	artifact.Hash = strings.ToLower(artifact.Hash)
 
-	verification, err := user.Sign(
+	ctx, cancel := cli.WithTimeout(r.Context())
+	defer cancel()
+
+	verification, err := user.SignContext(
+		ctx,
 		artifact,
 		opts...,
 	)
@@ -91,14 +104,18 @@ func sign(status meta.Status, kinds map[string]bool, w http.ResponseWriter, r *h
 	api.TrackPublisher(user, meta.VcnSignEvent)
 	api.TrackSign(user, artifact.Hash, artifact.Name, status)
 
+	if err = keys.authError(user, err); err == errTokenExpired {
+		writeError(w, http.StatusUnauthorized, err)
+		return
+	}
 	if err != nil {
-		writeError(w, http.StatusBadRequest, err)
+		writeErrorContext(ctx, w, http.StatusBadRequest, err)
 		return
 	}
 
 	var ar *api.ArtifactResponse
 	if !verification.Unknown() {
-		ar, _ = api.LoadArtifact(user, artifact.Hash, verification.MetaHash())
+		ar, _ = api.LoadArtifactContext(ctx, user, artifact.Hash, verification.MetaHash())
 	}
 
 	writeResult(w, http.StatusOK, types.NewResult(&artifact, ar, verification))
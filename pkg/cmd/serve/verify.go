@@ -15,20 +15,40 @@ import (
 	"github.com/gorilla/mux"
 
 	"github.com/vchain-us/vcn/pkg/api"
+	"github.com/vchain-us/vcn/pkg/cmd/internal/cli"
 	"github.com/vchain-us/vcn/pkg/cmd/internal/types"
 	"github.com/vchain-us/vcn/pkg/meta"
 )
 
-func verify(w http.ResponseWriter, r *http.Request) {
+func verifyHandler(creds apiKeys) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		verify(creds, w, r)
+	}
+}
+
+func verify(creds apiKeys, w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := cli.WithTimeout(r.Context())
+	defer cancel()
+
 	vars := mux.Vars(r)
 	hash := strings.ToLower(vars["hash"])
 
+	var minLevel *meta.Level
+	if ml := r.URL.Query().Get("min-level"); ml != "" {
+		l, err := meta.ParseLevel(ml)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		minLevel = &l
+	}
+
 	var keys []string
 	org := r.URL.Query().Get("org")
 	if org != "" {
-		bo, err := api.GetBlockChainOrganisation(org)
+		bo, err := api.GetBlockChainOrganisationContext(ctx, org)
 		if err != nil {
-			writeError(w, http.StatusBadRequest, err)
+			writeErrorContext(ctx, w, http.StatusBadRequest, err)
 			return
 		}
 		keys = bo.MembersIDs()
@@ -48,43 +68,47 @@ func verify(w http.ResponseWriter, r *http.Request) {
 
 	var err error
 	var verification *api.BlockchainVerification
-	user, _, err := getCredential(r)
+	user, _, err := getCredential(r, creds, false)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, err)
+		writeError(w, http.StatusUnauthorized, err)
 		return
 	}
 
 	// if keys have been passed, check for a verification matching them
 	if len(keys) > 0 {
-		verification, err = api.VerifyMatchingSignerIDs(hash, keys)
+		verification, err = api.VerifyMatchingSignerIDsContext(ctx, hash, keys)
 	} else {
 		// if we have an user, check for verification matching user's key first
 		userKey := ""
 		if user != nil {
 			userKey, err = user.SignerID()
+			if err = creds.authError(user, err); err == errTokenExpired {
+				writeError(w, http.StatusUnauthorized, err)
+				return
+			}
 			if err != nil {
 				writeError(w, http.StatusConflict, err)
 				return
 			}
 		}
 		if userKey != "" {
-			verification, err = api.VerifyMatchingSignerIDWithFallback(hash, userKey)
+			verification, err = api.VerifyMatchingSignerIDWithFallbackContext(ctx, hash, userKey)
 		} else {
 			// if no passed keys nor user,
 			// just get the last with highest level available verification
-			verification, err = api.Verify(hash)
+			verification, err = api.VerifyContext(ctx, hash)
 		}
 	}
 
 	if err != nil {
-		writeError(w, http.StatusConflict, err)
+		writeErrorContext(ctx, w, http.StatusConflict, err)
 		return
 	}
 
 	name := ""
 	var artifact *api.ArtifactResponse
 	if !verification.Unknown() {
-		artifact, _ = api.LoadArtifact(user, hash, verification.MetaHash())
+		artifact, _ = api.LoadArtifactContext(ctx, user, hash, verification.MetaHash())
 		if artifact != nil {
 			name = artifact.Name
 		}
@@ -94,5 +118,9 @@ func verify(w http.ResponseWriter, r *http.Request) {
 	api.TrackPublisher(user, meta.VcnVerifyEvent)
 	api.TrackVerify(user, hash, name)
 
-	writeResult(w, http.StatusOK, types.NewResult(nil, artifact, verification))
+	result := types.NewResult(nil, artifact, verification)
+	if minLevel != nil {
+		result.Rejection = types.NewLevelRejection(hash, verification, *minLevel)
+	}
+	writeResult(w, http.StatusOK, result)
 }
@@ -0,0 +1,213 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package sign
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/caarlos0/spin"
+	"github.com/fatih/color"
+	"github.com/vchain-us/vcn/pkg/api"
+	"github.com/vchain-us/vcn/pkg/cmd/internal/cli"
+	"github.com/vchain-us/vcn/pkg/cmd/internal/types"
+	"github.com/vchain-us/vcn/pkg/extractor"
+	"github.com/vchain-us/vcn/pkg/meta"
+)
+
+var hashRegExp = regexp.MustCompile("^[0-9a-f]{64}$")
+
+func isGlob(arg string) bool {
+	return !strings.Contains(arg, "://") && strings.ContainsAny(arg, "*?[")
+}
+
+func hasGlob(args []string) bool {
+	for _, arg := range args {
+		if isGlob(arg) {
+			return true
+		}
+	}
+	return false
+}
+
+// batchInputs returns the list of inputs made by args, with glob patterns expanded,
+// followed by the ones listed in fromFile, if any.
+func batchInputs(args []string, fromFile string) ([]string, error) {
+	inputs := []string{}
+	for _, arg := range args {
+		if !isGlob(arg) {
+			inputs = append(inputs, arg)
+			continue
+		}
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no asset matching %s", arg)
+		}
+		inputs = append(inputs, matches...)
+	}
+
+	if fromFile != "" {
+		f, err := os.Open(fromFile)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		lines, err := readInputs(f)
+		if err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, lines...)
+	}
+
+	if len(inputs) == 0 {
+		return nil, fmt.Errorf("no assets to notarize")
+	}
+	return inputs, nil
+}
+
+// readInputs reads one input per line from r, skipping empty lines and comments (#).
+func readInputs(r io.Reader) ([]string, error) {
+	inputs := []string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		inputs = append(inputs, line)
+	}
+	return inputs, scanner.Err()
+}
+
+// batchArtifacts returns the artifacts for the given input, that can be either a hash or an URI
+// (possibly referring to multiple artifacts, like a range of git commits).
+func batchArtifacts(u *api.User, input string, options ...extractor.Option) ([]*api.Artifact, error) {
+	if hash := strings.ToLower(input); hashRegExp.MatchString(hash) {
+		if _, err := os.Stat(input); os.IsNotExist(err) {
+			// Load existing artifact, if any, otherwise use the hash as name
+			if ar, err := u.LoadArtifact(hash); err == nil && ar != nil {
+				return []*api.Artifact{ar.Artifact()}, nil
+			}
+			return []*api.Artifact{{Hash: hash, Name: hash}}, nil
+		}
+	}
+
+	artifacts, err := extractor.ExtractAll(input, options...)
+	if err != nil {
+		return nil, err
+	}
+	if len(artifacts) == 0 {
+		return nil, fmt.Errorf("unable to process the input asset provided: %s", input)
+	}
+	return artifacts, nil
+}
+
+func signBatch(u api.User, artifacts []*api.Artifact, state meta.Status, visibility meta.Visibility, output string) error {
+
+	if output == "" {
+		color.Set(meta.StyleAffordance())
+		fmt.Println("Your assets will not be uploaded but processed locally.")
+		color.Unset()
+		fmt.Println()
+		fmt.Println("Signer:\t" + u.Email())
+		fmt.Printf("Assets:\t%d\n", len(artifacts))
+	}
+
+	hooks := make([]*hook, len(artifacts))
+	batch := make([]api.Artifact, len(artifacts))
+	for i, a := range artifacts {
+		hooks[i] = newHook(a)
+		batch[i] = *a
+	}
+
+	s := spin.New("%s Notarization in progress...")
+	s.Set(spin.Spin1)
+
+	var verifications []*api.BlockchainVerification
+	var errs []error
+	err := signWithKey(u, s, output, func(keyin io.Reader, passphrase string) (err error) {
+		ctx, cancel := cli.Context()
+		defer cancel()
+		verifications, errs, err = u.SignBatchContext(
+			ctx,
+			batch,
+			api.SignWithStatus(state),
+			api.SignWithVisibility(visibility),
+			api.SignWithKey(keyin, passphrase),
+		)
+		return
+	})
+
+	if output == "" {
+		s.Stop()
+	}
+	if err != nil {
+		return err
+	}
+
+	// track notarized assets only
+	tracked := false
+	for i, a := range batch {
+		if errs[i] != nil {
+			continue
+		}
+		if !tracked {
+			// todo(ameingast/leogr): remove reduntat event - need backend improvement
+			api.TrackPublisher(&u, meta.VcnSignEvent)
+			tracked = true
+		}
+		api.TrackSign(&u, a.Hash, a.Name, state)
+	}
+
+	if output == "" {
+		fmt.Println()
+	}
+
+	ctx, cancel := cli.Context()
+	defer cancel()
+
+	failed := 0
+	results := make([]types.Result, len(batch))
+	for i := range batch {
+		a := &batch[i]
+		if errs[i] != nil {
+			failed++
+			results[i] = *types.NewResult(a, nil, nil)
+			results[i].AddError(errs[i])
+			continue
+		}
+
+		if err := hooks[i].finalize(verifications[i]); err != nil {
+			return err
+		}
+
+		ar, err := api.LoadArtifactContext(ctx, &u, a.Hash, verifications[i].MetaHash())
+		results[i] = *types.NewResult(a, ar, verifications[i])
+		if err != nil {
+			results[i].AddError(err)
+		}
+	}
+
+	if err := cli.PrintSlice(output, results); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d notarizations failed", failed, len(batch))
+	}
+	return nil
+}
@@ -21,5 +21,8 @@ func noArgsWhenHash(cmd *cobra.Command, args []string) error {
 		}
 		return nil
 	}
-	return cobra.ExactArgs(1)(cmd, args)
+	if fromFile, _ := cmd.Flags().GetString("from-file"); fromFile != "" {
+		return nil
+	}
+	return cobra.MinimumNArgs(1)(cmd, args)
 }
@@ -9,11 +9,14 @@
 package sign
 
 import (
+	"encoding/json"
 	"path/filepath"
 
 	"github.com/vchain-us/vcn/pkg/api"
 	"github.com/vchain-us/vcn/pkg/bundle"
+	"github.com/vchain-us/vcn/pkg/extractor/archive"
 	"github.com/vchain-us/vcn/pkg/extractor/dir"
+	"github.com/vchain-us/vcn/pkg/store"
 )
 
 type hook struct {
@@ -26,6 +29,7 @@ func newHook(a *api.Artifact) *hook {
 			a: a.Copy(),
 		}
 		dir.RemoveMetadata(a)
+		archive.RemoveMetadata(a)
 		return &h
 	}
 	return nil
@@ -37,7 +41,21 @@ func (h *hook) finalize(v *api.BlockchainVerification) error {
 		if manifest != nil && path != "" {
 			// manifest is optional, we can ignore errors
 			bundle.WriteManifest(*manifest, filepath.Join(path, bundle.ManifestFilename))
+			storeManifest(h.a.Hash, manifest)
+		}
+		manifest, path = archive.Metadata(h.a)
+		if manifest != nil && path != "" {
+			// manifest is optional, we can ignore errors
+			bundle.WriteManifest(*manifest, archive.ManifestFile(path))
+			storeManifest(h.a.Hash, manifest)
 		}
 	}
 	return nil
 }
+
+// storeManifest keeps a local copy of manifest (see vcn diff), errors are ignored since it's optional.
+func storeManifest(hash string, manifest *bundle.Manifest) {
+	if data, err := json.Marshal(manifest); err == nil {
+		store.WriteManifest(hash, data)
+	}
+}
@@ -14,11 +14,13 @@ import (
 	"strings"
 
 	"github.com/vchain-us/vcn/pkg/extractor/dir"
+	"github.com/vchain-us/vcn/pkg/extractor/registry"
 
 	"github.com/fatih/color"
 
 	"github.com/caarlos0/spin"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 	"github.com/vchain-us/vcn/internal/assert"
 	"github.com/vchain-us/vcn/pkg/api"
 	"github.com/vchain-us/vcn/pkg/cmd/internal/cli"
@@ -35,13 +37,18 @@ required notarization password in a non-interactive environment.
 `
 
 const helpMsgFooter = `
-ARG must be one of:
+Each ARG must be one of:
   <file>
   file://<file>
   dir://<directory>
-  git://<repository>
+  git://<repository>[@<ref>|@<from>..<to>]
+  git-tree://<repository>[@<ref>|@<from>..<to>]
+  tar://<archive>
+  zip://<archive>
   docker://<image>
   podman://<image>
+  oci://<layout-or-tarball>[:<ref>]
+  registry://<host>/<repository>[:<tag>]
 `
 
 // NewCommand returns the cobra command for `vcn sign`
@@ -64,12 +71,18 @@ signed hash, signed status, level, and timestamp together.
 
 Note that your asset will not be uploaded but processed locally.
 
-Assets are referenced by passed ARG with notarization only accepting 
-1 ARG at a time.
+Assets are referenced by passed ARG(s), glob patterns are accepted too. 
+When more than one asset is passed, or --from-file is used, all assets 
+are notarized at once, so the notarization password is asked only once.
 ` + helpMsgFooter,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runSignWithState(cmd, args, meta.StatusTrusted)
 		},
+		PreRun: func(cmd *cobra.Command, args []string) {
+			// Bind to all flags to env vars (after flags were parsed),
+			// but only ones retrivied by using viper will be used.
+			viper.BindPFlags(cmd.Flags())
+		},
 		Args: noArgsWhenHash,
 	}
 
@@ -77,9 +90,14 @@ Assets are referenced by passed ARG with notarization only accepting
 	cmd.Flags().StringP("name", "n", "", "set the asset name")
 	cmd.Flags().BoolP("public", "p", false, "when notarized as public, the asset name and metadata will be visible to everyone")
 	cmd.Flags().String("hash", "", "specify the hash instead of using an asset, if set no ARG(s) can be used")
+	cmd.Flags().String("from-file", "", "read the assets to be notarized from the given file, one URI or hash per line")
 	cmd.Flags().Bool("no-ignore-file", false, "if set, .vcnignore will be not written inside the targeted dir")
+	cmd.Flags().Bool("extra-ignore-files", false, "when processing directories, honour .gitignore and .dockerignore files too")
+	cmd.Flags().Int("hash-workers", 0, "when processing directories, hash up to N files concurrently (0 means the number of CPUs)\n(overrides VCN_HASH_WORKERS env var, if any)")
+	viper.BindEnv("hash-workers", "VCN_HASH_WORKERS")
+	cmd.Flags().Bool("merkle", false, "when processing directories, use the Merkle root of the manifest as hash,\nso that single files can be later authenticated by using inclusion proofs (see vcn bundle proof)")
 	cmd.SetUsageTemplate(
-		strings.Replace(cmd.UsageTemplate(), "{{.UseLine}}", "{{.UseLine}} ARG", 1),
+		strings.Replace(cmd.UsageTemplate(), "{{.UseLine}}", "{{.UseLine}} ARG(s)", 1),
 	)
 
 	return cmd
@@ -98,6 +116,24 @@ func runSignWithState(cmd *cobra.Command, args []string, state meta.Status) erro
 		extractorOptions = append(extractorOptions, dir.WithIgnoreFileInit())
 	}
 
+	extraIgnoreFiles, err := cmd.Flags().GetBool("extra-ignore-files")
+	if err != nil {
+		return err
+	}
+	if extraIgnoreFiles {
+		extractorOptions = append(extractorOptions, dir.WithExtraIgnoreFiles())
+	}
+
+	extractorOptions = append(extractorOptions, dir.WithWorkers(viper.GetInt("hash-workers")))
+
+	merkle, err := cmd.Flags().GetBool("merkle")
+	if err != nil {
+		return err
+	}
+	if merkle {
+		extractorOptions = append(extractorOptions, dir.WithMerkleRoot())
+	}
+
 	var hash string
 	if hashFlag := cmd.Flags().Lookup("hash"); hashFlag != nil {
 		var err error
@@ -122,10 +158,26 @@ func runSignWithState(cmd *cobra.Command, args []string, state meta.Status) erro
 		return err
 	}
 
+	fromFile, err := cmd.Flags().GetString("from-file")
+	if err != nil {
+		return err
+	}
+
 	metadata := cmd.Flags().Lookup("attr").Value.(mapOpts).StringToInterface()
 
 	cmd.SilenceUsage = true
 
+	if output == "" {
+		progress := cli.NewProgress("%s Hashing files... %d/%d")
+		defer progress.Stop()
+		extractorOptions = append(extractorOptions, dir.WithProgress(progress.Update))
+	}
+
+	// remote assets (eg. registry://) are fetched within --timeout
+	ctx, cancel := cli.Context()
+	defer cancel()
+	extractorOptions = append(extractorOptions, registry.WithContext(ctx))
+
 	// User
 	if err := assert.UserLogin(); err != nil {
 		return err
@@ -135,6 +187,29 @@ func runSignWithState(cmd *cobra.Command, args []string, state meta.Status) erro
 		return fmt.Errorf("cannot load the current user")
 	}
 
+	// Batch notarization
+	if hash == "" && (fromFile != "" || len(args) > 1 || hasGlob(args)) {
+		if name != "" {
+			return fmt.Errorf("cannot use --name with multiple assets")
+		}
+		inputs, err := batchInputs(args, fromFile)
+		if err != nil {
+			return err
+		}
+		artifacts := []*api.Artifact{}
+		for _, input := range inputs {
+			as, err := batchArtifacts(u, input, extractorOptions...)
+			if err != nil {
+				return err
+			}
+			artifacts = append(artifacts, as...)
+		}
+		for _, a := range artifacts {
+			a.Metadata.SetValues(metadata)
+		}
+		return signBatch(*u, artifacts, state, meta.VisibilityForFlag(public), output)
+	}
+
 	// Make the artifact to be signed
 	var a *api.Artifact
 	if hash != "" {
@@ -149,11 +224,24 @@ func runSignWithState(cmd *cobra.Command, args []string, state meta.Status) erro
 			a = &api.Artifact{Hash: hash}
 		}
 	} else {
-		// Extract artifact from arg
-		a, err = extractor.Extract(args[0], extractorOptions...)
+		// Extract artifact(s) from arg
+		artifacts, err := extractor.ExtractAll(args[0], extractorOptions...)
 		if err != nil {
 			return err
 		}
+		// A single arg referring to multiple artifacts (eg. a range of git commits)
+		if len(artifacts) > 1 {
+			if name != "" {
+				return fmt.Errorf("cannot use --name with multiple assets")
+			}
+			for _, a := range artifacts {
+				a.Metadata.SetValues(metadata)
+			}
+			return signBatch(*u, artifacts, state, meta.VisibilityForFlag(public), output)
+		}
+		if len(artifacts) == 1 {
+			a = artifacts[0]
+		}
 	}
 
 	if a == nil {
@@ -187,8 +275,53 @@ func sign(u api.User, a api.Artifact, state meta.Status, visibility meta.Visibil
 	s.Set(spin.Spin1)
 
 	var verification *api.BlockchainVerification
-	var err error
+	err := signWithKey(u, s, output, func(keyin io.Reader, passphrase string) (err error) {
+		ctx, cancel := cli.Context()
+		defer cancel()
+		verification, err = u.SignContext(
+			ctx,
+			a,
+			api.SignWithStatus(state),
+			api.SignWithVisibility(visibility),
+			api.SignWithKey(keyin, passphrase),
+		)
+		return
+	})
+
+	// todo(ameingast/leogr): remove reduntat event - need backend improvement
+	api.TrackPublisher(&u, meta.VcnSignEvent)
+	api.TrackSign(&u, a.Hash, a.Name, state)
+
+	if output == "" {
+		s.Stop()
+	}
+	if err != nil {
+		return err
+	}
 
+	err = hook.finalize(verification)
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		fmt.Println()
+	}
+
+	ctx, cancel := cli.Context()
+	defer cancel()
+	artifact, err := api.LoadArtifactContext(ctx, &u, a.Hash, verification.MetaHash())
+	if err != nil {
+		return err
+	}
+
+	cli.Print(output, types.NewResult(&a, artifact, verification))
+	return nil
+}
+
+// signWithKey provides the passphrase and the User's secret to signFn,
+// and retries up to 3 times if the passphrase was wrong.
+func signWithKey(u api.User, s *spin.Spinner, output string, signFn func(keyin io.Reader, passphrase string) error) (err error) {
 	for i := 1; true; i++ {
 		var passphrase string
 		var interactive bool
@@ -211,12 +344,7 @@ func sign(u api.User, a api.Artifact, state meta.Status, visibility meta.Visibil
 			return fmt.Errorf("offline secret is not supported by the current vcn version")
 		}
 
-		verification, err = u.Sign(
-			a,
-			api.SignWithStatus(state),
-			api.SignWithVisibility(visibility),
-			api.SignWithKey(keyin, passphrase),
-		)
+		err = signFn(keyin, passphrase)
 
 		if err != nil && i >= 3 {
 			s.Stop()
@@ -230,32 +358,5 @@ func sign(u api.User, a api.Artifact, state meta.Status, visibility meta.Visibil
 		}
 		break
 	}
-
-	// todo(ameingast/leogr): remove reduntat event - need backend improvement
-	api.TrackPublisher(&u, meta.VcnSignEvent)
-	api.TrackSign(&u, a.Hash, a.Name, state)
-
-	if output == "" {
-		s.Stop()
-	}
-	if err != nil {
-		return err
-	}
-
-	err = hook.finalize(verification)
-	if err != nil {
-		return err
-	}
-
-	if output == "" {
-		fmt.Println()
-	}
-
-	artifact, err := api.LoadArtifact(&u, a.Hash, verification.MetaHash())
-	if err != nil {
-		return err
-	}
-
-	cli.Print(output, types.NewResult(&a, artifact, verification))
-	return nil
+	return
 }
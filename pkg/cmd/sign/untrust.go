@@ -36,8 +36,9 @@ signed hash, signed status, level, and timestamp together.
 
 Note that your asset will not be uploaded but processed locally.
 
-Assets are referenced by passed ARG(s) with untrust command only accepting 
-1 ARG at a time. 
+Assets are referenced by passed ARG(s), glob patterns are accepted too. 
+When more than one asset is passed, or --from-file is used, all assets 
+are untrusted at once, so the notarization password is asked only once.
 ` + helpMsgFooter
 	return cmd
 }
@@ -9,6 +9,7 @@
 package verify
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 
@@ -16,6 +17,7 @@ import (
 	"github.com/vchain-us/vcn/pkg/bundle"
 
 	"github.com/vchain-us/vcn/pkg/api"
+	"github.com/vchain-us/vcn/pkg/extractor/archive"
 	"github.com/vchain-us/vcn/pkg/extractor/dir"
 )
 
@@ -31,49 +33,109 @@ func newHook(cmd *cobra.Command, a *api.Artifact) *hook {
 		}
 		h.rawDiff, _ = cmd.Flags().GetBool("raw-diff")
 		dir.RemoveMetadata(a)
+		archive.RemoveMetadata(a)
 		return &h
 	}
 	return nil
 }
 
-func (h *hook) finalize(v *api.BlockchainVerification, output string) error {
-	if h != nil && output == "" {
-		manifest, path := dir.Metadata(h.a)
-		if manifest != nil && path != "" {
-			oldManifest, err := bundle.ReadManifest(filepath.Join(path, bundle.ManifestFilename))
-			if err != nil {
-				fmt.Printf("Diff is unavailable because '%s' is missing or invalid.\n\n", bundle.ManifestFilename)
-				return nil // ignore missing or bad manifest
-			}
-			// check old manifest integrity
-			oldDigest, err := oldManifest.Digest()
-			if err != nil {
-				fmt.Printf("Diff is unavailable because '%s' is invalid.\n\n", bundle.ManifestFilename)
-				return nil // ignore bad manifest
-			}
-			v, err := api.Verify(oldDigest.Encoded())
-			if err != nil {
-				return err
+// manifest returns the manifest of h.a and the filename of its previously stored manifest, if any.
+func (h *hook) manifest() (manifest *bundle.Manifest, filename string) {
+	if manifest, path := dir.Metadata(h.a); manifest != nil && path != "" {
+		return manifest, filepath.Join(path, bundle.ManifestFilename)
+	}
+	if manifest, path := archive.Metadata(h.a); manifest != nil && path != "" {
+		return manifest, archive.ManifestFile(path)
+	}
+	return nil, ""
+}
+
+// legacyHash returns the hash that h.a would have had with the legacy bundle.Manifest schema version,
+// or an empty string if it does not differ from the current one.
+func (h *hook) legacyHash() string {
+	if h == nil {
+		return ""
+	}
+	manifest, _ := h.manifest()
+	if manifest == nil || manifest.SchemaVersion == bundle.ManifestSchemaVersionLegacy {
+		return ""
+	}
+	// legacy manifests had no Merkle root
+	if d, err := manifest.Digest(); err != nil || d.Encoded() != h.a.Hash {
+		return ""
+	}
+	legacy, err := manifest.V1()
+	if err != nil {
+		return ""
+	}
+	d, err := legacy.Digest()
+	if err != nil || d.Encoded() == h.a.Hash {
+		return ""
+	}
+	return d.Encoded()
+}
+
+// finalize returns the differences between the manifest of h.a and its previously stored manifest, if any.
+// Differences are printed too, unless output is set.
+func (h *hook) finalize(ctx context.Context, v *api.BlockchainVerification, output string) (*bundle.PathDiff, error) {
+	if h == nil {
+		return nil, nil
+	}
+	manifest, filename := h.manifest()
+	if manifest == nil {
+		return nil, nil
+	}
+
+	printf := func(format string, a ...interface{}) {
+		if output == "" {
+			fmt.Printf(format, a...)
+		}
+	}
+
+	name := filepath.Base(filename)
+	oldManifest, err := bundle.ReadManifest(filename)
+	if err != nil {
+		printf("Diff is unavailable because '%s' is missing or invalid.\n\n", name)
+		return nil, nil // ignore missing or bad manifest
+	}
+	// check old manifest integrity
+	oldDigest, err := oldManifest.Digest()
+	if err != nil {
+		printf("Diff is unavailable because '%s' is invalid.\n\n", name)
+		return nil, nil // ignore bad manifest
+	}
+	v, err = api.VerifyContext(ctx, oldDigest.Encoded())
+	if err != nil {
+		return nil, err
+	}
+	// the old manifest may have been notarized by its Merkle root
+	if v.Unknown() {
+		if oldRoot, err := oldManifest.MerkleRoot(); err == nil {
+			if v, err = api.VerifyContext(ctx, oldRoot.Encoded()); err != nil {
+				return nil, err
 			}
-			if v != nil && !v.Unknown() {
-				var report string
-				var equal bool
-				var err error
-				if h.rawDiff {
-					report, equal, err = manifest.Diff(*oldManifest)
-				} else {
-					report, equal, err = manifest.DiffByPath(*oldManifest)
-				}
-				if err != nil {
-					return err
-				}
-				if !equal {
-					fmt.Printf("Diff since %s\n\n%s\n\n", v.Date(), report)
-				}
-			} else {
-				fmt.Printf("Diff is unavailable because '%s' has been tampered.\n\n", bundle.ManifestFilename)
+		}
+	}
+	if v.Unknown() {
+		printf("Diff is unavailable because '%s' has been tampered.\n\n", name)
+		return nil, nil
+	}
+
+	diff, err := manifest.DiffByPath(*oldManifest)
+	if err != nil {
+		return nil, err
+	}
+	if diff.Equal() {
+		return nil, nil
+	}
+	if output == "" {
+		report := diff.String()
+		if h.rawDiff {
+			if report, _, err = manifest.Diff(*oldManifest); err != nil {
+				return nil, err
 			}
 		}
+		fmt.Printf("Diff since %s\n\n%s\n\n", v.Date(), report)
 	}
-	return nil
+	return diff, nil
 }
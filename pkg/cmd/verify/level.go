@@ -0,0 +1,38 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package verify
+
+import (
+	"github.com/spf13/viper"
+	"github.com/vchain-us/vcn/pkg/api"
+	"github.com/vchain-us/vcn/pkg/cmd/internal/types"
+	"github.com/vchain-us/vcn/pkg/meta"
+)
+
+// minLevel returns the level set by --min-level (or VCN_MIN_LEVEL), if any, otherwise nil.
+func minLevel() (*meta.Level, error) {
+	s := viper.GetString("min-level")
+	if s == "" {
+		return nil, nil
+	}
+	l, err := meta.ParseLevel(s)
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// levelRejection returns a *types.Rejection if v is TRUSTED but below the level set by --min-level.
+func levelRejection(a *api.Artifact, v *api.BlockchainVerification) (*types.Rejection, error) {
+	l, err := minLevel()
+	if err != nil || l == nil {
+		return nil, err
+	}
+	return types.NewLevelRejection(a.Hash, v, *l), nil
+}
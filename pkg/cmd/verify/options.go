@@ -0,0 +1,55 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package verify
+
+import (
+	"io/ioutil"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/vchain-us/vcn/pkg/extractor"
+	"github.com/vchain-us/vcn/pkg/extractor/dir"
+	"github.com/vchain-us/vcn/pkg/extractor/git"
+)
+
+// extractorOptions returns the extractors options according to cmd's flags.
+func extractorOptions(cmd *cobra.Command) ([]extractor.Option, error) {
+	options := []extractor.Option{}
+
+	extraIgnoreFiles, err := cmd.Flags().GetBool("extra-ignore-files")
+	if err != nil {
+		return nil, err
+	}
+	if extraIgnoreFiles {
+		options = append(options, dir.WithExtraIgnoreFiles())
+	}
+	options = append(options, dir.WithWorkers(viper.GetInt("hash-workers")))
+
+	merkle, err := cmd.Flags().GetBool("merkle")
+	if err != nil {
+		return nil, err
+	}
+	if merkle {
+		options = append(options, dir.WithMerkleRoot())
+	}
+
+	keyRingFile, err := cmd.Flags().GetString("git-keyring")
+	if err != nil {
+		return nil, err
+	}
+	if keyRingFile != "" {
+		keyRing, err := ioutil.ReadFile(keyRingFile)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, git.WithKeyRing(string(keyRing)))
+	}
+
+	return options, nil
+}
@@ -0,0 +1,197 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package verify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/vchain-us/vcn/pkg/api"
+	"github.com/vchain-us/vcn/pkg/extractor"
+	"github.com/vchain-us/vcn/pkg/meta"
+	"github.com/vchain-us/vcn/pkg/policy"
+)
+
+// job authenticates all the artifacts referenced by arg.
+type job struct {
+	arg       string
+	artifacts []*api.Artifact
+	results   []*jobResult
+	err       error
+	done      chan struct{}
+}
+
+type jobResult struct {
+	a            *api.Artifact
+	keys         []string
+	hook         *hook
+	verification *api.BlockchainVerification
+	quorum       *api.Quorum
+	ar           *api.ArtifactResponse
+	err          error
+}
+
+func newJob(arg string, artifacts []*api.Artifact) *job {
+	return &job{
+		arg:       arg,
+		artifacts: artifacts,
+		done:      make(chan struct{}),
+	}
+}
+
+func (j *job) run(ctx context.Context, cmd *cobra.Command, options []extractor.Option, keys []string, pol *policy.Policy, userKey string, user *api.User) {
+	defer close(j.done)
+
+	// artifacts may be already extracted
+	if j.artifacts == nil {
+		j.artifacts, j.err = extractor.ExtractAll(j.arg, options...)
+		if j.err != nil {
+			return
+		}
+		if len(j.artifacts) == 0 {
+			j.err = fmt.Errorf("unable to process the input asset provided: %s", j.arg)
+			return
+		}
+	}
+
+	j.results = make([]*jobResult, len(j.artifacts))
+	for i, a := range j.artifacts {
+		j.results[i] = verifyJobArtifact(ctx, cmd, a, keys, pol, userKey, user)
+	}
+}
+
+func verifyJobArtifact(ctx context.Context, cmd *cobra.Command, a *api.Artifact, keys []string, pol *policy.Policy, userKey string, user *api.User) *jobResult {
+	r := &jobResult{
+		a:    a,
+		keys: policyKeys(pol, a, keys),
+		hook: newHook(cmd, a),
+	}
+	if len(r.keys) > 0 {
+		keys = r.keys
+		userKey = ""
+	}
+
+	r.verification, r.err = lookup(ctx, a.Hash, keys, userKey)
+	if r.err != nil {
+		r.err = fmt.Errorf("unable to authenticate the hash: %s", r.err)
+		return r
+	}
+	r.verification = lookupLegacy(ctx, a, r.hook, r.verification, keys, userKey)
+	r.quorum, r.verification, r.err = lookupQuorum(ctx, cmd, a.Hash, keys, r.verification)
+	if r.err != nil {
+		return r
+	}
+
+	if !r.verification.Unknown() {
+		r.ar, _ = api.LoadArtifactContext(ctx, user, a.Hash, r.verification.MetaHash())
+	}
+
+	track(user, a)
+	return r
+}
+
+// verifyParallel authenticates args by using up to n concurrent workers.
+// Results are reported in the same order of args, and all args are processed
+// even if some of them are not trusted.
+func verifyParallel(ctx context.Context, cmd *cobra.Command, args []string, options []extractor.Option, n uint, keys []string, org string, pol *policy.Policy, receipts *receiptWriter, user *api.User, output string) error {
+	jobs := make([]*job, len(args))
+	for i, arg := range args {
+		jobs[i] = newJob(arg, nil)
+	}
+	return runJobs(ctx, cmd, jobs, options, n, keys, org, pol, receipts, user, output)
+}
+
+// verifyAll authenticates all artifacts referenced by arg (eg. a range of git commits).
+// All artifacts are processed even if some of them are not trusted.
+func verifyAll(ctx context.Context, cmd *cobra.Command, arg string, artifacts []*api.Artifact, keys []string, org string, pol *policy.Policy, receipts *receiptWriter, user *api.User, output string) error {
+	return runJobs(ctx, cmd, []*job{newJob(arg, artifacts)}, nil, 1, keys, org, pol, receipts, user, output)
+}
+
+func runJobs(ctx context.Context, cmd *cobra.Command, jobs []*job, options []extractor.Option, n uint, keys []string, org string, pol *policy.Policy, receipts *receiptWriter, user *api.User, output string) error {
+	userKey := ""
+	if len(keys) == 0 {
+		if hasAuth, _ := user.IsAuthenticated(); hasAuth {
+			userKey, _ = user.SignerID()
+		}
+	}
+
+	if output == "" {
+		color.Set(meta.StyleAffordance())
+		fmt.Println("Your asset(s) will not be uploaded but processed locally.")
+		color.Unset()
+		fmt.Println()
+		switch true {
+		case org != "":
+			fmt.Printf("Looking for blockchain entries matching the organization (%s)...\n", org)
+		case len(keys) > 0:
+			fmt.Printf("Looking for blockchain entries matching the passed SignerIDs...\n")
+		case pol != nil && len(pol.Allow) > 0:
+			fmt.Printf("Looking for blockchain entries matching the policy's SignerIDs...\n")
+		case userKey != "":
+			fmt.Printf("Looking for blockchain entries matching the current user (%s)...\n", user.Email())
+		default:
+			fmt.Printf("Looking for the last blockchain entries with highest level available...\n")
+		}
+		fmt.Println()
+	}
+
+	// workers
+	queue := make(chan *job)
+	for i := uint(0); i < n; i++ {
+		go func() {
+			for j := range queue {
+				j.run(ctx, cmd, options, keys, pol, userKey, user)
+			}
+		}()
+	}
+	go func() {
+		for _, j := range jobs {
+			queue <- j
+		}
+		close(queue)
+	}()
+
+	// report in order, as soon as each job is done
+	errs := []string{}
+	total := 0
+	for _, j := range jobs {
+		<-j.done
+		if j.err != nil {
+			total++
+			errs = append(errs, j.err.Error())
+			continue
+		}
+		for _, r := range j.results {
+			total++
+			err := r.err
+			if err == nil {
+				err = report(ctx, cmd, r.a, r.hook, r.verification, r.quorum, r.ar, r.keys, org, pol, receipts, output)
+			}
+			if err != nil {
+				// tell which one failed, when arg refers to multiple artifacts
+				if len(j.results) > 1 {
+					err = fmt.Errorf("%s: %s", r.a.Name, err)
+				}
+				errs = append(errs, err.Error())
+			}
+		}
+	}
+
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return fmt.Errorf("%s", errs[0])
+	default:
+		return fmt.Errorf("%d of %d assets could not be authenticated:\n%s", len(errs), total, strings.Join(errs, "\n"))
+	}
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package verify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/vchain-us/vcn/pkg/api"
+	"github.com/vchain-us/vcn/pkg/policy"
+)
+
+// loadPolicy returns the policy set by --policy (or VCN_POLICY), if any.
+func loadPolicy(ctx context.Context) (*policy.Policy, error) {
+	filename := viper.GetString("policy")
+	if filename == "" {
+		return nil, nil
+	}
+	pol, err := policy.Load(filename)
+	if err != nil {
+		return nil, err
+	}
+	if err := pol.Resolve(ctx); err != nil {
+		return nil, err
+	}
+	return pol, nil
+}
+
+// policyKeys returns keys if not empty, otherwise the SignerIDs allowed by pol for a, if any.
+func policyKeys(pol *policy.Policy, a *api.Artifact, keys []string) []string {
+	if len(keys) > 0 || pol == nil {
+		return keys
+	}
+	return pol.SignerIDs(a.Kind)
+}
+
+// evaluatePolicy returns the result of pol against the authentication of a.
+func evaluatePolicy(
+	ctx context.Context,
+	pol *policy.Policy,
+	a *api.Artifact,
+	ar *api.ArtifactResponse,
+	verification *api.BlockchainVerification,
+) (*policy.Result, error) {
+	s := policy.Subject{
+		Kind:         a.Kind,
+		Verification: verification,
+		Now:          time.Now(),
+	}
+	if ar != nil {
+		s.Metadata = ar.Metadata
+		// the notarized kind is chosen by the signer, so trust it only when the asset's one is unknown (i.e. --hash)
+		if s.Kind == "" {
+			s.Kind = ar.Kind
+		}
+	}
+	if pol.NeedsHistory() && !verification.Unknown() {
+		history, err := api.BlockChainInspectContext(ctx, a.Hash)
+		if err != nil {
+			return nil, fmt.Errorf("unable to evaluate the policy: %s", err)
+		}
+		s.History = history
+	}
+	return pol.Evaluate(s), nil
+}
+
+// policyError returns the error describing the rules that r failed, if any.
+func policyError(a *api.Artifact, r *policy.Result) error {
+	if r == nil || r.Passed {
+		return nil
+	}
+	reasons := []string{}
+	for _, rr := range r.Failed() {
+		reasons = append(reasons, fmt.Sprintf("%s (%s)", rr.Rule, rr.Reason))
+	}
+	return fmt.Errorf("%s does not satisfy the policy: %s", a.Hash, strings.Join(reasons, ", "))
+}
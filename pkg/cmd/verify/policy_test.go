@@ -0,0 +1,63 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package verify
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/vchain-us/vcn/pkg/api"
+	"github.com/vchain-us/vcn/pkg/meta"
+	"github.com/vchain-us/vcn/pkg/policy"
+)
+
+func TestEvaluatePolicyKind(t *testing.T) {
+	signer := "0x0000000000000000000000000000000000000001"
+
+	tdir, err := ioutil.TempDir("", "vcn-policy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tdir)
+	filename := filepath.Join(tdir, "policy.yaml")
+	if err := ioutil.WriteFile(filename, []byte("allow:\n  - kinds: [file]\n    signers: ["+signer+"]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pol, err := policy.Load(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verification := &api.BlockchainVerification{
+		Owner:     common.HexToAddress(signer),
+		Level:     meta.LevelEmailVerified,
+		Status:    meta.StatusTrusted,
+		Timestamp: time.Now(),
+	}
+	// the signer notarized a docker image as a file
+	ar := &api.ArtifactResponse{Kind: "file"}
+
+	r, err := evaluatePolicy(context.Background(), pol, &api.Artifact{Kind: "docker"}, ar, verification)
+	assert.NoError(t, err)
+	assert.False(t, r.Passed)
+	if failed := r.Failed(); assert.Len(t, failed, 1) {
+		assert.Equal(t, policy.RuleAllow, failed[0].Rule)
+	}
+
+	// the notarized kind is used when the asset's one is unknown
+	r, err = evaluatePolicy(context.Background(), pol, &api.Artifact{}, ar, verification)
+	assert.NoError(t, err)
+	assert.True(t, r.Passed)
+}
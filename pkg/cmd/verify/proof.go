@@ -0,0 +1,49 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package verify
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/vchain-us/vcn/pkg/api"
+	"github.com/vchain-us/vcn/pkg/bundle"
+	"github.com/vchain-us/vcn/pkg/extractor/dir"
+)
+
+// proofArtifact returns an *api.Artifact having, as hash, the Merkle root proven by the proof
+// stored in proofFile for the file named by filename.
+func proofArtifact(filename string, proofFile string) (*api.Artifact, error) {
+	p, err := bundle.ReadProof(proofFile)
+	if err != nil {
+		return nil, err
+	}
+	root, err := p.Verify()
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := dir.Descriptor(filename, p.Item.Paths[0])
+	if err != nil {
+		return nil, err
+	}
+	// legacy manifests do not record modes
+	if p.Item.Mode == "" && (d.Mode == bundle.ModeFile || d.Mode == bundle.ModeExecutable) {
+		d.Mode = ""
+	}
+	if d.Digest != p.Item.Digest || d.Size != p.Item.Size || d.Mode != p.Item.Mode || d.Target != p.Item.Target {
+		return nil, fmt.Errorf("%s does not match the proven item %s", filename, p.Item.Paths[0])
+	}
+
+	return &api.Artifact{
+		Kind: dir.Scheme,
+		Name: path.Base(p.Item.Paths[0]),
+		Hash: root.Encoded(),
+	}, nil
+}
@@ -0,0 +1,42 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package verify
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/vchain-us/vcn/pkg/api"
+)
+
+// lookupQuorum returns the quorum of keys for hash when --quorum is set, otherwise nil.
+// If the quorum is reached, the most recent TRUSTED notarization among keys is returned in place of v.
+func lookupQuorum(ctx context.Context, cmd *cobra.Command, hash string, keys []string, v *api.BlockchainVerification) (*api.Quorum, *api.BlockchainVerification, error) {
+	n, _ := cmd.Flags().GetUint("quorum")
+	if n == 0 {
+		return nil, v, nil
+	}
+	q, err := api.VerifyQuorumContext(ctx, hash, keys, n)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to authenticate the hash: %s", err)
+	}
+	if qv := q.Verification(); qv != nil {
+		v = qv
+	}
+	return q, v, nil
+}
+
+// quorumError returns the error describing why q has not been reached, if so.
+func quorumError(a *api.Artifact, q *api.Quorum) error {
+	if q == nil || q.Reached() {
+		return nil
+	}
+	return fmt.Errorf("%s is trusted by %d of %d required signers", a.Hash, q.Trusted, q.Required)
+}
@@ -0,0 +1,150 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package verify
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/spf13/cobra"
+	"github.com/vchain-us/vcn/internal/assert"
+	"github.com/vchain-us/vcn/pkg/api"
+	"github.com/vchain-us/vcn/pkg/cmd/internal/cli"
+	"github.com/vchain-us/vcn/pkg/cmd/internal/types"
+	"github.com/vchain-us/vcn/pkg/meta"
+)
+
+const receiptExt = ".receipt.json"
+
+// receiptWriter writes the receipts of authenticated assets into dir, signed by key.
+type receiptWriter struct {
+	dir string
+	key *ecdsa.PrivateKey
+}
+
+// newReceiptWriter returns a *receiptWriter if --receipt is set, otherwise nil.
+// Receipts are signed by the secret of user, that must be logged in.
+func newReceiptWriter(cmd *cobra.Command, user *api.User) (*receiptWriter, error) {
+	dir, _ := cmd.Flags().GetString("receipt")
+	if dir == "" {
+		return nil, nil
+	}
+	if err := assert.UserLogin(); err != nil {
+		return nil, err
+	}
+
+	keyin, _, offline, err := user.Secret()
+	if err != nil {
+		return nil, err
+	}
+	if offline {
+		return nil, fmt.Errorf("offline secret is not supported by the current vcn version")
+	}
+	passphrase, _, err := cli.ProvidePassphrase()
+	if err != nil {
+		return nil, err
+	}
+	buf := new(bytes.Buffer)
+	if _, err := buf.ReadFrom(keyin); err != nil {
+		return nil, err
+	}
+	key, err := keystore.DecryptKey(buf.Bytes(), passphrase)
+	if err == keystore.ErrDecrypt {
+		return nil, api.WrongPassphraseErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &receiptWriter{dir: dir, key: key.PrivateKey}, nil
+}
+
+func (rw *receiptWriter) write(ctx context.Context, a *api.Artifact, ar *api.ArtifactResponse, v *api.BlockchainVerification) (string, error) {
+	txHash := ""
+	if ar != nil {
+		txHash = ar.TxHash
+	}
+	r, err := api.NewReceipt(ctx, a.Hash, v, txHash)
+	if err != nil {
+		return "", err
+	}
+	if err := r.Sign(rw.key); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(rw.dir, 0755); err != nil {
+		return "", err
+	}
+	filename := filepath.Join(rw.dir, a.Hash+receiptExt)
+	return filename, r.WriteFile(filename)
+}
+
+// verifyFromReceipt authenticates a against the receipt file named by filename.
+// The receipt must be signed by any of signerIDs, even if online is true,
+// since the receipt's owner is the one checked against the blockchain.
+func verifyFromReceipt(ctx context.Context, cmd *cobra.Command, a *api.Artifact, filename string, signerIDs []string, online bool, output string) error {
+	r, err := api.LoadReceipt(filename)
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		fmt.Printf("Checking receipt %s...\n", filename)
+	}
+	if err := r.Check(a.Hash); err != nil {
+		return err
+	}
+	if err := r.CheckSignature(signerIDs); err != nil {
+		return err
+	}
+
+	verification := &r.Verification
+	if online {
+		if output == "" {
+			fmt.Printf("Checking receipt against the blockchain...\n")
+		}
+		verification, err = r.CheckOnline(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	if output == "" {
+		fmt.Println()
+	}
+
+	result := types.NewResult(a, nil, verification)
+	if result.Rejection, err = levelRejection(a, verification); err != nil {
+		return err
+	}
+	if err = cli.Print(output, result); err != nil {
+		return err
+	}
+
+	if output != "" {
+		cmd.SilenceErrors = true
+	}
+
+	if !verification.Trusted() {
+		return fmt.Errorf("%s %s", a.Hash, map[meta.Status]string{
+			meta.StatusUnknown:     "was not notarized",
+			meta.StatusUntrusted:   "is untrusted",
+			meta.StatusUnsupported: "is unsupported",
+		}[verification.Status])
+	}
+
+	if result.Rejection != nil {
+		return result.Rejection
+	}
+
+	return checkSignature(cmd, a)
+}
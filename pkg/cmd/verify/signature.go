@@ -0,0 +1,34 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package verify
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/vchain-us/vcn/pkg/api"
+	"github.com/vchain-us/vcn/pkg/extractor/git"
+)
+
+// checkSignature returns an error if a valid commit signature is required by --git-keyring,
+// but a's one was not verified.
+func checkSignature(cmd *cobra.Command, a *api.Artifact) error {
+	if keyRingFile, _ := cmd.Flags().GetString("git-keyring"); keyRingFile == "" {
+		return nil
+	}
+
+	s := git.CommitSignature(a)
+	if s == nil {
+		return fmt.Errorf("%s is not a git commit, cannot check its signature", a.Hash)
+	}
+	if !s.Verified {
+		return fmt.Errorf("%s has not a valid signature from an allowed key: %s", a.Hash, s.Error)
+	}
+	return nil
+}
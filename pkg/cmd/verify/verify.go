@@ -9,6 +9,7 @@
 package verify
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"strings"
@@ -21,7 +22,10 @@ import (
 	"github.com/vchain-us/vcn/pkg/cmd/internal/cli"
 	"github.com/vchain-us/vcn/pkg/cmd/internal/types"
 	"github.com/vchain-us/vcn/pkg/extractor"
+	"github.com/vchain-us/vcn/pkg/extractor/dir"
+	"github.com/vchain-us/vcn/pkg/extractor/registry"
 	"github.com/vchain-us/vcn/pkg/meta"
+	"github.com/vchain-us/vcn/pkg/policy"
 	"github.com/vchain-us/vcn/pkg/store"
 )
 
@@ -58,6 +62,32 @@ Note that your assets will not be uploaded but processed locally.
 The exit code will be 0 only if all assets' statuses are equal to TRUSTED. 
 Otherwise, the exit code will be 1.
 
+When --min-level is used, TRUSTED assets notarized by signers having a lower
+level (see vcn info) are rejected, and the exit code will be 1.
+
+When --quorum is used, at least N distinct signers among the passed SignerIDs
+(or the organization's members) must currently trust the asset, that is the 
+most recent status set by each of them must be TRUSTED. 
+Otherwise, the exit code will be 1.
+
+When --policy is used, the given policy file (YAML) sets further rules that
+each asset must satisfy, such as a minimum signer level, the signers allowed
+per asset kind, a maximum notarization age or required metadata.
+The result of each rule is reported, and the exit code will be 0 only if
+all rules are satisfied. Otherwise, the exit code will be 1.
+
+When --cache-ttl (or VCN_CACHE_TTL) is set, results are cached locally 
+(see vcn cache) for that time, but never more than 30s for assets that 
+are not trusted. The cache is disabled by default. Use --no-cache to 
+bypass it.
+
+When --receipt is used, a portable receipt is written for each authenticated 
+asset, signed by the current user's secret. Receipts can be later used by 
+--from-receipt to authenticate the same asset again without network access 
+(e.g. on air-gapped targets), as long as they are signed by any of the 
+SignerID(s) passed by --receipt-signer, that is always required. Adding 
+--online, receipts are re-checked against the blockchain too.
+
 Assets are referenced by the passed ARG(s), with authentication accepting 
 1 or more ARG(s) at a time. Multiple assets can be authenticated at the 
 same time while passing them within ARG(s).
@@ -66,9 +96,14 @@ ARG must be one of:
   <file>
   file://<file>
   dir://<directory>
-  git://<repository>
+  git://<repository>[@<ref>|@<from>..<to>]
+  git-tree://<repository>[@<ref>|@<from>..<to>]
+  tar://<archive>
+  zip://<archive>
   docker://<image>
   podman://<image>
+  oci://<layout-or-tarball>[:<ref>]
+  registry://<host>/<repository>[:<tag>]
 `,
 		RunE: runVerify,
 		PreRun: func(cmd *cobra.Command, args []string) {
@@ -83,12 +118,44 @@ ARG must be one of:
 				}
 			}
 
+			if fromReceipt, _ := cmd.Flags().GetString("from-receipt"); fromReceipt != "" {
+				if receipt, _ := cmd.Flags().GetString("receipt"); receipt != "" {
+					return fmt.Errorf("cannot use both --receipt and --from-receipt")
+				}
+				if org := viper.GetString("org"); org != "" || len(getSignerIDs()) > 0 {
+					return fmt.Errorf("cannot use --from-receipt with --org or SignerID(s)")
+				}
+			} else {
+				if online, _ := cmd.Flags().GetBool("online"); online {
+					return fmt.Errorf("--online can be used only with --from-receipt")
+				}
+				if signers, _ := cmd.Flags().GetStringSlice("receipt-signer"); len(signers) > 0 {
+					return fmt.Errorf("--receipt-signer can be used only with --from-receipt")
+				}
+			}
+
+			if proof, _ := cmd.Flags().GetString("proof"); proof != "" {
+				if hash, _ := cmd.Flags().GetString("hash"); hash != "" {
+					return fmt.Errorf("cannot use both --proof and --hash")
+				}
+				if fromReceipt, _ := cmd.Flags().GetString("from-receipt"); fromReceipt != "" {
+					return fmt.Errorf("cannot use both --proof and --from-receipt")
+				}
+				return cobra.ExactArgs(1)(cmd, args)
+			}
+
 			if hash, _ := cmd.Flags().GetString("hash"); hash != "" {
 				if len(args) > 0 {
 					return fmt.Errorf("cannot use ARG(s) with --hash")
 				}
+				if keyRing, _ := cmd.Flags().GetString("git-keyring"); keyRing != "" {
+					return fmt.Errorf("cannot use --git-keyring with --hash")
+				}
 				return nil
 			}
+			if fromReceipt, _ := cmd.Flags().GetString("from-receipt"); fromReceipt != "" {
+				return cobra.ExactArgs(1)(cmd, args)
+			}
 			return cobra.MinimumNArgs(1)(cmd, args)
 		},
 	}
@@ -101,7 +168,26 @@ ARG must be one of:
 	cmd.Flags().StringSliceP("key", "k", nil, "")
 	cmd.Flags().MarkDeprecated("key", "please use --signerID instead")
 	cmd.Flags().StringP("org", "I", "", "accept only authentications matching the passed organisation's ID,\nif set no SignerID can be used\n(overrides VCN_ORG env var, if any)")
+	cmd.Flags().String("min-level", "", "reject authentications made by signers having a level lower than the given one,\neither its value or its name (e.g. 3 or ID_VERIFIED)\n(overrides VCN_MIN_LEVEL env var, if any)")
+	viper.BindEnv("min-level", "VCN_MIN_LEVEL")
+	cmd.Flags().Uint("quorum", 0, "require at least N distinct signers among the passed SignerID(s) or the organisation's members\nto currently trust the asset (0 means any single signer)")
 	cmd.Flags().String("hash", "", "specify a hash to authenticate, if set no ARG(s) can be used")
+	cmd.Flags().String("receipt", "", "write a receipt (<hash>.receipt.json) for each authenticated asset into the given directory")
+	cmd.Flags().String("from-receipt", "", "authenticate the asset against the given receipt file, without network access")
+	cmd.Flags().Bool("online", false, "when used with --from-receipt, also check the receipt against the blockchain")
+	cmd.Flags().StringSlice("receipt-signer", nil, "when used with --from-receipt, accept only receipts signed by any of the passed SignerID(s)")
+	cmd.Flags().Bool("no-cache", false, "do not use the local verification cache")
+	cmd.Flags().Duration("cache-ttl", 0, "time-to-live of the local verification cache for trusted assets, 0 disables the cache\n(overrides VCN_CACHE_TTL env var, if any)")
+	viper.BindEnv("cache-ttl", "VCN_CACHE_TTL")
+	cmd.Flags().Uint("parallel", 1, "authenticate up to N assets concurrently, results are printed in the same order of ARG(s)")
+	cmd.Flags().Bool("extra-ignore-files", false, "when processing directories, honour .gitignore and .dockerignore files too")
+	cmd.Flags().Int("hash-workers", 0, "when processing directories, hash up to N files concurrently (0 means the number of CPUs)\n(overrides VCN_HASH_WORKERS env var, if any)")
+	viper.BindEnv("hash-workers", "VCN_HASH_WORKERS")
+	cmd.Flags().Bool("merkle", false, "when processing directories, use the Merkle root of the manifest as hash (see vcn notarize --merkle)")
+	cmd.Flags().String("proof", "", "authenticate the single file passed as ARG against the root of a notarized directory,\nby using the given inclusion proof file (see vcn bundle proof)")
+	cmd.Flags().String("policy", "", "accept only authentications satisfying the rules within the given policy file\n(overrides VCN_POLICY env var, if any)")
+	viper.BindEnv("policy", "VCN_POLICY")
+	cmd.Flags().String("git-keyring", "", "require git commits to have a valid PGP signature made by a key within the given armored keyring file")
 	cmd.Flags().Bool("raw-diff", false, "print raw a diff, if any")
 	cmd.Flags().MarkHidden("raw-diff")
 
@@ -119,12 +205,107 @@ func runVerify(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	fromReceipt, err := cmd.Flags().GetString("from-receipt")
+	if err != nil {
+		return err
+	}
+
+	online, err := cmd.Flags().GetBool("online")
+	if err != nil {
+		return err
+	}
+
+	parallel, err := cmd.Flags().GetUint("parallel")
+	if err != nil {
+		return err
+	}
+
+	noCache, err := cmd.Flags().GetBool("no-cache")
+	if err != nil {
+		return err
+	}
+
+	proof, err := cmd.Flags().GetString("proof")
+	if err != nil {
+		return err
+	}
+
+	quorum, err := cmd.Flags().GetUint("quorum")
+	if err != nil {
+		return err
+	}
+
+	if _, err := minLevel(); err != nil {
+		return err
+	}
+
+	extractorOptions, err := extractorOptions(cmd)
+	if err != nil {
+		return err
+	}
+	// show the hashing progress, unless assets are processed concurrently
+	if output == "" && (parallel <= 1 || len(args) <= 1) {
+		progress := cli.NewProgress("%s Hashing files... %d/%d")
+		defer progress.Stop()
+		extractorOptions = append(extractorOptions, dir.WithProgress(progress.Update))
+	}
+
 	cmd.SilenceUsage = true
 
+	if noCache {
+		api.SetCacheTTL(0)
+	} else {
+		api.SetCacheTTL(viper.GetDuration("cache-ttl"))
+	}
+
+	ctx, cancel := cli.Context()
+	defer cancel()
+	extractorOptions = append(extractorOptions, registry.WithContext(ctx))
+
+	if fromReceipt != "" {
+		if viper.GetString("policy") != "" {
+			return fmt.Errorf("cannot use --policy with --from-receipt")
+		}
+		if quorum > 0 {
+			return fmt.Errorf("cannot use --quorum with --from-receipt")
+		}
+		signers, err := cmd.Flags().GetStringSlice("receipt-signer")
+		if err != nil {
+			return err
+		}
+		if len(signers) == 0 {
+			return fmt.Errorf("--from-receipt requires --receipt-signer")
+		}
+		for i, k := range signers {
+			if !strings.HasPrefix(k, "0x") {
+				signers[i] = "0x" + k
+			}
+			signers[i] = strings.ToLower(signers[i])
+			if !keyRegExp.MatchString(signers[i]) {
+				return fmt.Errorf("invalid public address format: %s", k)
+			}
+		}
+		var a *api.Artifact
+		if hash != "" {
+			a = &api.Artifact{
+				Hash: strings.ToLower(hash),
+			}
+		} else {
+			a, err = extractor.Extract(args[0], extractorOptions...)
+			if err != nil {
+				return err
+			}
+			if a == nil {
+				return fmt.Errorf("unable to process the input asset provided: %s", args[0])
+			}
+		}
+		return verifyFromReceipt(ctx, cmd, a, fromReceipt, signers, online, output)
+	}
+
 	org := viper.GetString("org")
 	var keys []string
 	if org != "" {
-		bo, err := api.GetBlockChainOrganisation(org)
+		bo, err := api.GetBlockChainOrganisationContext(ctx, org)
 		if err != nil {
 			return err
 		}
@@ -143,29 +324,61 @@ func runVerify(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if quorum > 0 && len(keys) == 0 {
+		return fmt.Errorf("--quorum can be used only with --org or SignerID(s)")
+	}
+
+	pol, err := loadPolicy(ctx)
+	if err != nil {
+		return err
+	}
+
 	user := api.NewUser(store.Config().CurrentContext)
 
+	receipts, err := newReceiptWriter(cmd, user)
+	if err != nil {
+		return err
+	}
+
+	// by inclusion proof
+	if proof != "" {
+		a, err := proofArtifact(args[0], proof)
+		if err != nil {
+			return err
+		}
+		return verify(ctx, cmd, a, keys, org, pol, receipts, user, output)
+	}
+
 	// by hash
 	if hash != "" {
 		a := &api.Artifact{
 			Hash: strings.ToLower(hash),
 		}
-		if err := verify(cmd, a, keys, org, user, output); err != nil {
+		if err := verify(ctx, cmd, a, keys, org, pol, receipts, user, output); err != nil {
 			return err
 		}
 		return nil
 	}
 
 	// else by args
+	if parallel > 1 && len(args) > 1 {
+		return verifyParallel(ctx, cmd, args, extractorOptions, parallel, keys, org, pol, receipts, user, output)
+	}
 	for _, arg := range args {
-		a, err := extractor.Extract(arg)
+		artifacts, err := extractor.ExtractAll(arg, extractorOptions...)
 		if err != nil {
 			return err
 		}
-		if a == nil {
+		if len(artifacts) == 0 {
 			return fmt.Errorf("unable to process the input asset provided: %s", arg)
 		}
-		if err := verify(cmd, a, keys, org, user, output); err != nil {
+		if len(artifacts) > 1 {
+			if err := verifyAll(ctx, cmd, arg, artifacts, keys, org, pol, receipts, user, output); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := verify(ctx, cmd, artifacts[0], keys, org, pol, receipts, user, output); err != nil {
 			return err
 		}
 	}
@@ -173,9 +386,12 @@ func runVerify(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func verify(cmd *cobra.Command, a *api.Artifact, keys []string, org string, user *api.User, output string) (err error) {
+func verify(ctx context.Context, cmd *cobra.Command, a *api.Artifact, keys []string, org string, pol *policy.Policy, receipts *receiptWriter, user *api.User, output string) (err error) {
 	hook := newHook(cmd, a)
+	passedKeys := len(keys) > 0
+	keys = policyKeys(pol, a, keys)
 	var verification *api.BlockchainVerification
+	userKey := ""
 	if output == "" {
 		color.Set(meta.StyleAffordance())
 		fmt.Println("Your asset(s) will not be uploaded but processed locally.")
@@ -185,17 +401,19 @@ func verify(cmd *cobra.Command, a *api.Artifact, keys []string, org string, user
 	// if keys have been passed, check for a verification matching them
 	if len(keys) > 0 {
 		if output == "" {
-			if org == "" {
+			switch true {
+			case !passedKeys:
+				fmt.Printf("Looking for blockchain entry matching the policy's SignerIDs...\n")
+			case org == "":
 				fmt.Printf("Looking for blockchain entry matching the passed SignerIDs...\n")
-			} else {
+			default:
 				fmt.Printf("Looking for blockchain entry matching the organization (%s)...\n", org)
 			}
 		}
-		verification, err = api.VerifyMatchingSignerIDs(a.Hash, keys)
+		verification, err = api.VerifyMatchingSignerIDsContext(ctx, a.Hash, keys)
 
 	} else {
 		// if we have an user, check for verification matching user's key first
-		userKey := ""
 		if hasAuth, _ := user.IsAuthenticated(); hasAuth {
 			userKey, _ = user.SignerID() // todo(leogr): double check this
 		}
@@ -203,7 +421,7 @@ func verify(cmd *cobra.Command, a *api.Artifact, keys []string, org string, user
 			if output == "" {
 				fmt.Printf("Looking for blockchain entry matching the current user (%s)...\n", user.Email())
 			}
-			verification, err = api.VerifyMatchingSignerIDWithFallback(a.Hash, userKey)
+			verification, err = api.VerifyMatchingSignerIDWithFallbackContext(ctx, a.Hash, userKey)
 			if output == "" {
 				if verification.SignerID() != userKey {
 					fmt.Printf("No blockchain entry matching the current user found.\n")
@@ -218,7 +436,7 @@ func verify(cmd *cobra.Command, a *api.Artifact, keys []string, org string, user
 			if output == "" {
 				fmt.Printf("Looking for the last blockchain entry with highest level available...\n")
 			}
-			verification, err = api.Verify(a.Hash)
+			verification, err = api.VerifyContext(ctx, a.Hash)
 		}
 	}
 
@@ -229,28 +447,110 @@ func verify(cmd *cobra.Command, a *api.Artifact, keys []string, org string, user
 	if err != nil {
 		return fmt.Errorf("unable to authenticate the hash: %s", err)
 	}
-
-	err = hook.finalize(verification, output)
+	verification = lookupLegacy(ctx, a, hook, verification, keys, userKey)
+	quorum, verification, err := lookupQuorum(ctx, cmd, a.Hash, keys, verification)
 	if err != nil {
 		return err
 	}
 
 	var ar *api.ArtifactResponse
 	if !verification.Unknown() {
-		ar, _ = api.LoadArtifact(user, a.Hash, verification.MetaHash())
+		ar, _ = api.LoadArtifactContext(ctx, user, a.Hash, verification.MetaHash())
 	}
 
-	if err = cli.Print(output, types.NewResult(a, ar, verification)); err != nil {
-		return err
+	track(user, a)
+
+	return report(ctx, cmd, a, hook, verification, quorum, ar, keys, org, pol, receipts, output)
+}
+
+// lookup returns the verification of hash, matching keys if any, otherwise preferring userKey if not empty.
+func lookup(ctx context.Context, hash string, keys []string, userKey string) (*api.BlockchainVerification, error) {
+	switch true {
+	case len(keys) > 0:
+		return api.VerifyMatchingSignerIDsContext(ctx, hash, keys)
+	case userKey != "":
+		return api.VerifyMatchingSignerIDWithFallbackContext(ctx, hash, userKey)
+	default:
+		return api.VerifyContext(ctx, hash)
 	}
+}
 
-	if output != "" {
-		cmd.SilenceErrors = true
+// lookupLegacy returns the verification of the legacy hash of a when v is unknown,
+// so that assets notarized with a former bundle.Manifest schema version can still be authenticated.
+// If found, a.Hash is set to the legacy hash, otherwise v is returned as is.
+func lookupLegacy(ctx context.Context, a *api.Artifact, hook *hook, v *api.BlockchainVerification, keys []string, userKey string) *api.BlockchainVerification {
+	if !v.Unknown() {
+		return v
+	}
+	legacy := hook.legacyHash()
+	if legacy == "" {
+		return v
+	}
+	if lv, err := lookup(ctx, legacy, keys, userKey); err == nil && !lv.Unknown() {
+		a.Hash = legacy
+		return lv
 	}
+	return v
+}
 
+func track(user *api.User, a *api.Artifact) {
 	// todo(ameingast/leogr): remove reduntat event - need backend improvement
 	api.TrackPublisher(user, meta.VcnVerifyEvent)
 	api.TrackVerify(user, a.Hash, a.Name)
+}
+
+// report prints the result of the authentication of a, and returns an error if a is not trusted.
+func report(
+	ctx context.Context,
+	cmd *cobra.Command,
+	a *api.Artifact,
+	hook *hook,
+	verification *api.BlockchainVerification,
+	quorum *api.Quorum,
+	ar *api.ArtifactResponse,
+	keys []string,
+	org string,
+	pol *policy.Policy,
+	receipts *receiptWriter,
+	output string,
+) (err error) {
+	diff, err := hook.finalize(ctx, verification, output)
+	if err != nil {
+		return err
+	}
+
+	r := types.NewResult(a, ar, verification)
+	r.Quorum = quorum
+	if r.Rejection, err = levelRejection(a, verification); err != nil {
+		return err
+	}
+	r.Diff = diff
+	if pol != nil {
+		if r.Policy, err = evaluatePolicy(ctx, pol, a, ar, verification); err != nil {
+			return err
+		}
+	}
+	if err = cli.Print(output, r); err != nil {
+		return err
+	}
+
+	if receipts != nil && !verification.Unknown() {
+		filename, err := receipts.write(ctx, a, ar, verification)
+		if err != nil {
+			return err
+		}
+		if output == "" {
+			fmt.Printf("Receipt written to %s\n", filename)
+		}
+	}
+
+	if output != "" {
+		cmd.SilenceErrors = true
+	}
+
+	if err := quorumError(a, quorum); err != nil {
+		return err
+	}
 
 	if !verification.Trusted() {
 		errLabels := map[meta.Status]string{
@@ -271,5 +571,13 @@ func verify(cmd *cobra.Command, a *api.Artifact, keys []string, org string, user
 		}
 	}
 
-	return
+	if r.Rejection != nil {
+		return r.Rejection
+	}
+
+	if err := policyError(a, r.Policy); err != nil {
+		return err
+	}
+
+	return checkSignature(cmd, a)
 }
@@ -0,0 +1,155 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package archive
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/vchain-us/vcn/pkg/api"
+	"github.com/vchain-us/vcn/pkg/bundle"
+	"github.com/vchain-us/vcn/pkg/extractor"
+	"github.com/vchain-us/vcn/pkg/uri"
+)
+
+// SchemeTar is the scheme for tar archives, optionally gzip or bzip2 compressed
+const SchemeTar = "tar"
+
+// SchemeZip is the scheme for zip archives
+const SchemeZip = "zip"
+
+// ManifestKey is the metadata's key for storing the manifest
+const ManifestKey = "manifest"
+
+// PathKey is the metadata's key for the archive path
+const PathKey = "path"
+
+var walkers = map[string]func(filename string) ([]bundle.Descriptor, error){
+	SchemeTar: walkTar,
+	SchemeZip: walkZip,
+}
+
+// Artifact returns an archive *api.Artifact from a given u.
+// The artifact's hash is the digest of a bundle.Manifest built from the archive's entries,
+// so it depends only on entries' paths, contents and modes.
+func Artifact(u *uri.URI, options ...extractor.Option) (*api.Artifact, error) {
+
+	walk, ok := walkers[u.Scheme]
+	if !ok {
+		return nil, nil
+	}
+
+	filename := strings.TrimPrefix(u.Opaque, "//")
+	filename, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	stat, err := os.Stat(filename)
+	if err != nil {
+		return nil, err
+	}
+	if !stat.Mode().IsRegular() {
+		return nil, fmt.Errorf("read %s: is not a regular file", filename)
+	}
+
+	files, err := walk(filename)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s archive %s: %s", u.Scheme, filename, err)
+	}
+
+	manifest := bundle.NewManifest(files...)
+	digest, err := manifest.Digest()
+	if err != nil {
+		return nil, err
+	}
+
+	// Metadata container
+	m := api.Metadata{
+		ManifestKey: manifest,
+		PathKey:     filename,
+	}
+
+	return &api.Artifact{
+		Kind:     u.Scheme,
+		Hash:     digest.Encoded(),
+		Name:     stat.Name(),
+		Metadata: m,
+	}, nil
+}
+
+// entries collects descriptors by path, so that only the last occurrence of a path is kept
+// (like when extracting an archive).
+type entries struct {
+	index map[string]int
+	items []bundle.Descriptor
+	dirs  []string
+}
+
+func newEntries() *entries {
+	return &entries{
+		index: map[string]int{},
+		items: make([]bundle.Descriptor, 0),
+		dirs:  make([]string, 0),
+	}
+}
+
+// add adds d, replacing any previous descriptor having the same path.
+func (e *entries) add(d *bundle.Descriptor) {
+	p := d.Paths[0]
+	if i, ok := e.index[p]; ok {
+		e.items[i] = *d
+		return
+	}
+	e.index[p] = len(e.items)
+	e.items = append(e.items, *d)
+}
+
+// addDir records the directory named p, that will be added as an item only if empty.
+func (e *entries) addDir(p string) {
+	e.dirs = append(e.dirs, p)
+}
+
+// descriptors returns the collected descriptors, including empty directories.
+func (e *entries) descriptors() []bundle.Descriptor {
+	notEmpty := make(map[string]bool)
+	markParents := func(p string) {
+		for p = path.Dir(p); p != "." && !notEmpty[p]; p = path.Dir(p) {
+			notEmpty[p] = true
+		}
+	}
+	for _, d := range e.items {
+		markParents(d.Paths[0])
+	}
+	// deepest first, since archives may list directories in any order
+	sort.Sort(sort.Reverse(sort.StringSlice(e.dirs)))
+	for _, p := range e.dirs {
+		if _, ok := e.index[p]; ok || notEmpty[p] {
+			continue
+		}
+		e.add(bundle.NewDirDescriptor(p))
+		markParents(p)
+	}
+	return e.items
+}
+
+// cleanName returns the OS agnostic relative path for the given entry name,
+// or an empty string if the entry must be skipped.
+func cleanName(name string) string {
+	p := path.Clean("/" + filepath.ToSlash(name))
+	p = strings.TrimPrefix(p, "/")
+	if p == "" || p == bundle.ManifestFilename {
+		return ""
+	}
+	return p
+}
@@ -0,0 +1,233 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vchain-us/vcn/pkg/extractor/dir"
+	"github.com/vchain-us/vcn/pkg/uri"
+)
+
+type entry struct {
+	name    string
+	content string
+}
+
+func writeTar(t *testing.T, filename string, compress bool, mtime time.Time, entries ...entry) {
+	f, err := os.Create(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var w io.Writer = f
+	if compress {
+		gw := gzip.NewWriter(f)
+		defer gw.Close()
+		w = gw
+	}
+	tw := tar.NewWriter(w)
+	defer tw.Close()
+	tw.WriteHeader(&tar.Header{Name: "./", Typeflag: tar.TypeDir, Mode: 0755, ModTime: mtime})
+	for _, e := range entries {
+		tw.WriteHeader(&tar.Header{
+			Name:     e.name,
+			Typeflag: tar.TypeReg,
+			Mode:     0644,
+			Size:     int64(len(e.content)),
+			ModTime:  mtime,
+		})
+		tw.Write([]byte(e.content))
+	}
+}
+
+func writeZip(t *testing.T, filename string, entries ...entry) {
+	f, err := os.Create(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	defer zw.Close()
+	for _, e := range entries {
+		w, _ := zw.Create(e.name)
+		w.Write([]byte(e.content))
+	}
+}
+
+func TestArtifact(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "vcn-archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	a := entry{"a.txt", "a"}
+	b := entry{"sub/b.txt", "b"}
+
+	plain := filepath.Join(tmpDir, "plain.tar")
+	writeTar(t, plain, false, time.Unix(0, 0), a, b)
+	u, _ := uri.Parse("tar://" + plain)
+	expected, err := Artifact(u)
+	assert.NoError(t, err)
+	assert.NotNil(t, expected)
+	assert.Equal(t, SchemeTar, expected.Kind)
+	assert.Equal(t, "plain.tar", expected.Name)
+	assert.Regexp(t, "^[0-9a-f]{64}$", expected.Hash)
+
+	// compression, timestamps and entries order do not matter
+	compressed := filepath.Join(tmpDir, "compressed.tar.gz")
+	writeTar(t, compressed, true, time.Now(), entry{"./" + b.name, b.content}, a)
+	u, _ = uri.Parse("tar://" + compressed)
+	got, err := Artifact(u)
+	assert.NoError(t, err)
+	assert.Equal(t, expected.Hash, got.Hash)
+
+	zipped := filepath.Join(tmpDir, "zipped.zip")
+	writeZip(t, zipped, a, b)
+	u, _ = uri.Parse("zip://" + zipped)
+	got, err = Artifact(u)
+	assert.NoError(t, err)
+	assert.Equal(t, SchemeZip, got.Kind)
+	assert.Equal(t, expected.Hash, got.Hash)
+
+	// same identity of the extracted content
+	extracted := filepath.Join(tmpDir, "extracted")
+	os.MkdirAll(filepath.Join(extracted, "sub"), 0755)
+	ioutil.WriteFile(filepath.Join(extracted, a.name), []byte(a.content), 0644)
+	ioutil.WriteFile(filepath.Join(extracted, b.name), []byte(b.content), 0644)
+	u, _ = uri.Parse("dir://" + extracted)
+	got, err = dir.Artifact(u)
+	assert.NoError(t, err)
+	assert.Equal(t, expected.Hash, got.Hash)
+
+	// content matters
+	changed := filepath.Join(tmpDir, "changed.tar")
+	writeTar(t, changed, false, time.Unix(0, 0), a, entry{b.name, "changed"})
+	u, _ = uri.Parse("tar://" + changed)
+	got, err = Artifact(u)
+	assert.NoError(t, err)
+	assert.NotEqual(t, expected.Hash, got.Hash)
+
+	// last occurrence of a path wins
+	duplicated := filepath.Join(tmpDir, "duplicated.tar")
+	writeTar(t, duplicated, false, time.Unix(0, 0), a, entry{b.name, "old"}, b)
+	u, _ = uri.Parse("tar://" + duplicated)
+	got, err = Artifact(u)
+	assert.NoError(t, err)
+	assert.Equal(t, expected.Hash, got.Hash)
+
+	// manifest metadata
+	manifest, path := Metadata(*got)
+	assert.NotNil(t, manifest)
+	assert.Equal(t, duplicated, path)
+	RemoveMetadata(got)
+	manifest, path = Metadata(*got)
+	assert.Nil(t, manifest)
+	assert.Empty(t, path)
+
+	// wrong scheme - SKIP (no error)
+	u, _ = uri.Parse("file://" + plain)
+	got, err = Artifact(u)
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+
+	// not an archive - ERROR
+	u, _ = uri.Parse("zip://" + plain)
+	got, err = Artifact(u)
+	assert.Error(t, err)
+	assert.Nil(t, got)
+
+	// not a file - ERROR
+	u, _ = uri.Parse("tar://" + tmpDir)
+	got, err = Artifact(u)
+	assert.Error(t, err)
+	assert.Nil(t, got)
+
+	// not existing - ERROR
+	u, _ = uri.Parse("tar://" + tmpDir + "/not-existing.tar")
+	got, err = Artifact(u)
+	assert.Error(t, err)
+	assert.Nil(t, got)
+}
+
+func TestArtifactModes(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "vcn-archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// tar
+	tarball := filepath.Join(tmpDir, "modes.tar")
+	f, err := os.Create(tarball)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tw := tar.NewWriter(f)
+	tw.WriteHeader(&tar.Header{Name: "run.sh", Typeflag: tar.TypeReg, Mode: 0755, Size: 1})
+	tw.Write([]byte("x"))
+	tw.WriteHeader(&tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "run.sh"})
+	tw.WriteHeader(&tar.Header{Name: "sub/", Typeflag: tar.TypeDir, Mode: 0755})
+	tw.WriteHeader(&tar.Header{Name: "sub/empty/", Typeflag: tar.TypeDir, Mode: 0755})
+	tw.Close()
+	f.Close()
+	u, _ := uri.Parse("tar://" + tarball)
+	expected, err := Artifact(u)
+	assert.NoError(t, err)
+	manifest, _ := Metadata(*expected)
+	assert.Len(t, manifest.Items, 3)
+
+	// zip
+	zipped := filepath.Join(tmpDir, "modes.zip")
+	f, err = os.Create(zipped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for _, e := range []struct {
+		name    string
+		mode    os.FileMode
+		content string
+	}{
+		{"run.sh", 0755, "x"},
+		{"link", 0777 | os.ModeSymlink, "run.sh"},
+		{"sub/empty/", 0755 | os.ModeDir, ""},
+	} {
+		fh := &zip.FileHeader{Name: e.name}
+		fh.SetMode(e.mode)
+		w, _ := zw.CreateHeader(fh)
+		w.Write([]byte(e.content))
+	}
+	zw.Close()
+	f.Close()
+	u, _ = uri.Parse("zip://" + zipped)
+	got, err := Artifact(u)
+	assert.NoError(t, err)
+	assert.Equal(t, expected.Hash, got.Hash)
+
+	// same identity of the extracted content
+	extracted := filepath.Join(tmpDir, "extracted")
+	os.MkdirAll(filepath.Join(extracted, "sub", "empty"), 0755)
+	ioutil.WriteFile(filepath.Join(extracted, "run.sh"), []byte("x"), 0755)
+	os.Symlink("run.sh", filepath.Join(extracted, "link"))
+	u, _ = uri.Parse("dir://" + extracted)
+	got, err = dir.Artifact(u)
+	assert.NoError(t, err)
+	assert.Equal(t, expected.Hash, got.Hash)
+}
@@ -0,0 +1,54 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package archive
+
+import (
+	"github.com/vchain-us/vcn/pkg/api"
+	"github.com/vchain-us/vcn/pkg/bundle"
+)
+
+// ManifestFile returns the filename of the manifest stored alongside the archive at path,
+// since it cannot be stored within the archive itself.
+func ManifestFile(path string) string {
+	return path + bundle.ManifestFilename
+}
+
+// Metadata extracts archive related info from a.
+func Metadata(a api.Artifact) (manifest *bundle.Manifest, path string) {
+	if walkers[a.Kind] == nil {
+		return
+	}
+
+	// Get manifest
+	m := a.Metadata[ManifestKey]
+	if m != nil {
+		if mm, ok := m.(*bundle.Manifest); ok {
+			manifest = mm
+		}
+	}
+
+	// Get path
+	p := a.Metadata[PathKey]
+	if p != nil {
+		if pp, ok := p.(string); ok {
+			path = pp
+		}
+	}
+
+	return
+}
+
+// RemoveMetadata removes archive related info from a.
+func RemoveMetadata(a *api.Artifact) {
+	if a == nil || walkers[a.Kind] == nil {
+		return
+	}
+	delete(a.Metadata, ManifestKey)
+	delete(a.Metadata, PathKey)
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package archive
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+	"os"
+
+	"github.com/vchain-us/vcn/pkg/bundle"
+)
+
+var (
+	gzipMagic  = []byte{0x1f, 0x8b}
+	bzip2Magic = []byte("BZh")
+)
+
+// Decompress returns a reader of the uncompressed content of r,
+// by detecting gzip and bzip2 compression from the leading magic bytes.
+func Decompress(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	head, err := br.Peek(3)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	switch true {
+	case bytes.HasPrefix(head, gzipMagic):
+		return gzip.NewReader(br)
+	case bytes.HasPrefix(head, bzip2Magic):
+		return bzip2.NewReader(br), nil
+	}
+	return br, nil
+}
+
+func walkTar(filename string) ([]bundle.Descriptor, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r, err := Decompress(f)
+	if err != nil {
+		return nil, err
+	}
+
+	files := newEntries()
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		name := cleanName(hdr.Name)
+		if name == "" {
+			continue
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			files.addDir(name)
+		case tar.TypeSymlink:
+			files.add(bundle.NewSymlinkDescriptor(name, hdr.Linkname))
+		case tar.TypeReg, tar.TypeRegA:
+			d, err := bundle.NewDescriptor(name, tr)
+			if err != nil {
+				return nil, err
+			}
+			d.Mode = bundle.FileMode(hdr.FileInfo().Mode())
+			files.add(d)
+		default:
+			// skip irregular files (e.g. hard link, pipe, device...)
+		}
+	}
+	return files.descriptors(), nil
+}
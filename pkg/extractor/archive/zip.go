@@ -0,0 +1,62 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package archive
+
+import (
+	"archive/zip"
+	"io/ioutil"
+	"os"
+
+	"github.com/vchain-us/vcn/pkg/bundle"
+)
+
+func walkZip(filename string) ([]bundle.Descriptor, error) {
+	zr, err := zip.OpenReader(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	files := newEntries()
+	for _, f := range zr.File {
+		name := cleanName(f.Name)
+		if name == "" {
+			continue
+		}
+		mode := f.FileInfo().Mode()
+		if mode.IsDir() {
+			files.addDir(name)
+			continue
+		}
+		// skip irregular files (e.g. pipe, device...)
+		if !mode.IsRegular() && mode&os.ModeSymlink == 0 {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		var d *bundle.Descriptor
+		if mode&os.ModeSymlink != 0 {
+			// symlink's target is stored as the entry's content
+			var target []byte
+			if target, err = ioutil.ReadAll(rc); err == nil {
+				d = bundle.NewSymlinkDescriptor(name, string(target))
+			}
+		} else if d, err = bundle.NewDescriptor(name, rc); err == nil {
+			d.Mode = bundle.FileMode(mode)
+		}
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		files.add(d)
+	}
+	return files.descriptors(), nil
+}
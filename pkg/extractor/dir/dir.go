@@ -14,6 +14,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	dgst "github.com/opencontainers/go-digest"
 	"github.com/vchain-us/vcn/pkg/api"
 	"github.com/vchain-us/vcn/pkg/bundle"
 	"github.com/vchain-us/vcn/pkg/extractor"
@@ -30,7 +31,11 @@ const ManifestKey = "manifest"
 const PathKey = "path"
 
 type opts struct {
-	initIgnoreFile bool
+	initIgnoreFile   bool
+	extraIgnoreFiles bool
+	workers          int
+	progress         func(done, total int)
+	merkleRoot       bool
 }
 
 // Artifact returns a file *api.Artifact from a given u
@@ -72,13 +77,18 @@ func Artifact(u *uri.URI, options ...extractor.Option) (*api.Artifact, error) {
 		}
 	}
 
-	files, err := walk(path)
+	files, err := walk(path, opts)
 	if err != nil {
 		return nil, err
 	}
 
 	manifest := bundle.NewManifest(files...)
-	digest, err := manifest.Digest()
+	var digest dgst.Digest
+	if opts.merkleRoot {
+		digest, err = manifest.MerkleRoot()
+	} else {
+		digest, err = manifest.Digest()
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -107,3 +117,48 @@ func WithIgnoreFileInit() extractor.Option {
 		return nil
 	}
 }
+
+// WithExtraIgnoreFiles returns a functional option to instruct the dir's extractor to honour
+// .gitignore and .dockerignore files too, in addition to .vcnignore ones.
+func WithExtraIgnoreFiles() extractor.Option {
+	return func(o interface{}) error {
+		if o, ok := o.(*opts); ok {
+			o.extraIgnoreFiles = true
+		}
+		return nil
+	}
+}
+
+// WithWorkers returns a functional option to instruct the dir's extractor to hash up to n files concurrently.
+// If n is less than 1, the number of CPUs is used (the default).
+func WithWorkers(n int) extractor.Option {
+	return func(o interface{}) error {
+		if o, ok := o.(*opts); ok {
+			o.workers = n
+		}
+		return nil
+	}
+}
+
+// WithProgress returns a functional option to instruct the dir's extractor to call fn each time a file
+// has been hashed, with the number of files hashed so far and the total number of files.
+func WithProgress(fn func(done, total int)) extractor.Option {
+	return func(o interface{}) error {
+		if o, ok := o.(*opts); ok {
+			o.progress = fn
+		}
+		return nil
+	}
+}
+
+// WithMerkleRoot returns a functional option to instruct the dir's extractor to use the Merkle root
+// of the manifest as the artifact's hash, instead of the manifest's digest,
+// so that inclusion proofs of single files can be produced later (see bundle.Manifest.Proof()).
+func WithMerkleRoot() extractor.Option {
+	return func(o interface{}) error {
+		if o, ok := o.(*opts); ok {
+			o.merkleRoot = true
+		}
+		return nil
+	}
+}
@@ -9,11 +9,14 @@
 package dir
 
 import (
+	"fmt"
 	"io/ioutil"
+	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/vchain-us/vcn/pkg/bundle"
 	"github.com/vchain-us/vcn/pkg/uri"
 )
 
@@ -65,3 +68,226 @@ func TestArtifact(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, a)
 }
+
+func TestIgnoreFiles(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "TempDir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	for name, content := range map[string]string{
+		IgnoreFilename:                      "*.log\nvendor/\n",
+		DockerIgnoreFilename:                "*.md\nbuild\n",
+		GitIgnoreFilename:                   "*.tmp\n",
+		"README.md":                         "",
+		"root.log":                          "",
+		"root.tmp":                          "",
+		"build/out":                         "",
+		"pkg/a/" + IgnoreFilename:           "!keep.log\n*.bin\n",
+		"pkg/a/keep.log":                    "",
+		"pkg/a/other.log":                   "",
+		"pkg/a/data.bin":                    "",
+		"pkg/a/doc.md":                      "",
+		"pkg/b/data.bin":                    "",
+		"pkg/b/" + GitIgnoreFilename:        "/local\n",
+		"pkg/b/local":                       "",
+		"pkg/b/sub/local":                   "",
+		"pkg/b/sub/" + DockerIgnoreFilename: "*\n",
+		"vendor/" + IgnoreFilename:          "!keep.txt\n",
+		"vendor/keep.txt":                   "",
+		"vendor/lib/file":                   "",
+	} {
+		filename := filepath.Join(tmpDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	paths := func(extra bool) []string {
+		files, err := walk(tmpDir, &opts{extraIgnoreFiles: extra})
+		if err != nil {
+			t.Fatal(err)
+		}
+		res := []string{}
+		for _, f := range files {
+			res = append(res, f.Paths...)
+		}
+		return res
+	}
+
+	// nested .vcnignore files only,
+	// files within ignored directories cannot be re-included (as for git)
+	assert.Equal(t, []string{
+		DockerIgnoreFilename,
+		GitIgnoreFilename,
+		IgnoreFilename,
+		"README.md",
+		"build/out",
+		"pkg/a/" + IgnoreFilename,
+		"pkg/a/doc.md",
+		"pkg/a/keep.log",
+		"pkg/b/" + GitIgnoreFilename,
+		"pkg/b/data.bin",
+		"pkg/b/local",
+		"pkg/b/sub/" + DockerIgnoreFilename,
+		"pkg/b/sub/local",
+		"root.tmp",
+	}, paths(false))
+
+	// .gitignore (nested) and .dockerignore (root only) too
+	assert.Equal(t, []string{
+		DockerIgnoreFilename,
+		GitIgnoreFilename,
+		IgnoreFilename,
+		"pkg/a/" + IgnoreFilename,
+		"pkg/a/doc.md",
+		"pkg/a/keep.log",
+		"pkg/b/" + GitIgnoreFilename,
+		"pkg/b/data.bin",
+		"pkg/b/sub/" + DockerIgnoreFilename,
+		"pkg/b/sub/local",
+	}, paths(true))
+
+	u, _ := uri.Parse("dir://" + tmpDir)
+	a1, err := Artifact(u)
+	assert.NoError(t, err)
+	a2, err := Artifact(u, WithExtraIgnoreFiles())
+	assert.NoError(t, err)
+	assert.NotEqual(t, a1.Hash, a2.Hash)
+}
+
+func TestWalkWorkers(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "TempDir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	for i := 0; i < 200; i++ {
+		filename := filepath.Join(tmpDir, fmt.Sprintf("d%d", i%7), fmt.Sprintf("f%03d", i))
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatal(err)
+		}
+		// some files share the same content
+		if err := ioutil.WriteFile(filename, []byte(fmt.Sprint(i%50)), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	digests := map[int]string{}
+	for _, workers := range []int{1, 0, 3, 16, 500} {
+		calls := 0
+		files, err := walk(tmpDir, &opts{
+			workers: workers,
+			progress: func(done, total int) {
+				calls++
+				assert.Equal(t, calls, done)
+				assert.Equal(t, 200, total)
+			},
+		})
+		assert.NoError(t, err)
+		assert.Len(t, files, 200)
+		assert.Equal(t, 200, calls)
+
+		d, err := bundle.NewManifest(files...).Digest()
+		assert.NoError(t, err)
+		digests[workers] = d.String()
+	}
+	for workers, d := range digests {
+		assert.Equal(t, digests[1], d, "workers: %d", workers)
+	}
+
+	// same as the extractor's hash
+	u, _ := uri.Parse("dir://" + tmpDir)
+	a, err := Artifact(u, WithWorkers(4))
+	assert.NoError(t, err)
+	assert.Equal(t, "sha256:"+a.Hash, digests[1])
+
+	// failure
+	_, err = digest(tmpDir, []entry{{"d0/f000", 0644}, {"missing", 0644}, {"d1/f001", 0644}}, 2, nil)
+	assert.Error(t, err)
+}
+
+func TestWalkModes(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "TempDir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	for _, d := range []string{"empty", "parent/empty", "ignored"} {
+		if err := os.MkdirAll(filepath.Join(tmpDir, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, mode := range map[string]os.FileMode{"file.txt": 0644, "run.sh": 0755, "ignored/file.txt": 0644} {
+		if err := ioutil.WriteFile(filepath.Join(tmpDir, name), []byte(name), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink("file.txt", filepath.Join(tmpDir, "link")); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(tmpDir, ".vcnignore"), []byte("ignored/\n.vcnignore\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := walk(tmpDir, &opts{})
+	assert.NoError(t, err)
+	modes := map[string]string{}
+	for _, f := range files {
+		modes[f.Paths[0]] = f.Mode
+		if f.Mode == bundle.ModeSymlink {
+			assert.Equal(t, "file.txt", f.Target)
+		}
+	}
+	assert.Equal(t, map[string]string{
+		"file.txt":     bundle.ModeFile,
+		"run.sh":       bundle.ModeExecutable,
+		"link":         bundle.ModeSymlink,
+		"empty":        bundle.ModeDir,
+		"parent/empty": bundle.ModeDir,
+	}, modes)
+
+	u, _ := uri.Parse("dir://" + tmpDir)
+	a1, err := Artifact(u)
+	assert.NoError(t, err)
+
+	// chmod -x
+	if err := os.Chmod(filepath.Join(tmpDir, "run.sh"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	a2, err := Artifact(u)
+	assert.NoError(t, err)
+	assert.NotEqual(t, a1.Hash, a2.Hash)
+
+	// retargeted symlink
+	os.Remove(filepath.Join(tmpDir, "link"))
+	if err := os.Symlink("run.sh", filepath.Join(tmpDir, "link")); err != nil {
+		t.Fatal(err)
+	}
+	a3, err := Artifact(u)
+	assert.NoError(t, err)
+	assert.NotEqual(t, a2.Hash, a3.Hash)
+
+	m1, _ := Metadata(*a1)
+	m3, _ := Metadata(*a3)
+	diff, err := m3.DiffByPath(*m1)
+	assert.NoError(t, err)
+	assert.False(t, diff.Equal())
+	report := diff.String()
+	assert.Contains(t, report, "mode changed: run.sh")
+	assert.Contains(t, report, "link retargeted: link")
+
+	// merkle root
+	a4, err := Artifact(u, WithMerkleRoot())
+	assert.NoError(t, err)
+	root, err := m3.MerkleRoot()
+	assert.NoError(t, err)
+	assert.Equal(t, root.Encoded(), a4.Hash)
+	assert.NotEqual(t, a3.Hash, a4.Hash)
+}
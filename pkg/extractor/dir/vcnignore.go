@@ -11,6 +11,7 @@ package dir
 import (
 	"io/ioutil"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
@@ -23,12 +24,25 @@ import (
 //  - https://git-scm.com/docs/gitignore
 //  - https://github.com/src-d/go-git/blob/master/plumbing/format/gitignore/doc.go
 //
+// Ignore files are honoured at every level of the directory tree: patterns within a nested ignore file
+// are relative to its directory and take precedence over the ones found in parent directories,
+// like .gitignore files do.
+//
 // However, this package implementation:
-//  - only uses the ignore file within the root directory (nested ignore files will be treated as normal files)
 //  - always ignores the manifest file (it cannot be excluded by the ignore file)
+//  - still includes ignore files into the manifest (unless they are excluded by an ignore pattern)
 //
 const IgnoreFilename = ".vcnignore"
 
+// GitIgnoreFilename is the name of git's ignore file, honoured when WithExtraIgnoreFiles() is used.
+// Nested .gitignore files are supported too, with the same semantics of IgnoreFilename.
+const GitIgnoreFilename = ".gitignore"
+
+// DockerIgnoreFilename is the name of docker's ignore file, honoured when WithExtraIgnoreFiles() is used.
+// As docker does, only the .dockerignore file within the root directory is used,
+// and its patterns are always relative to the root directory.
+const DockerIgnoreFilename = ".dockerignore"
+
 // DefaultIgnoreFileContent is the content of ignore file with default patterns.
 const DefaultIgnoreFileContent = `# Windows thumbnail cache files
 Thumbs.db
@@ -84,35 +98,61 @@ const (
 	ignorefileEOL           = "\n"
 )
 
-type nullMatcher struct{}
-
-func (n *nullMatcher) Match(path []string, isDir bool) bool {
-	return false
+// ignoreFilenames returns the names of ignore files to be read within the directory at domain,
+// in ascending order of priority.
+func ignoreFilenames(domain []string, extra bool) []string {
+	switch true {
+	case !extra:
+		return []string{IgnoreFilename}
+	case len(domain) == 0:
+		return []string{DockerIgnoreFilename, GitIgnoreFilename, IgnoreFilename}
+	default:
+		return []string{GitIgnoreFilename, IgnoreFilename}
+	}
 }
 
-// newIgnoreFileMatcher reads and parses the ignore file in path and return a gitignore.Matcher.
-// If the ignore file does not exists, a matcher that will never match is returned instead.
-func newIgnoreFileMatcher(path string) (m gitignore.Matcher, err error) {
-	f, err := os.Open(filepath.Join(path, IgnoreFilename))
-	if err != nil {
-		if os.IsNotExist(err) {
-			return &nullMatcher{}, nil
+// readIgnorePatterns reads and parses the ignore files within the directory at domain (relative to root),
+// and returns their patterns in ascending order of priority (last higher), similarly to gitignore.ReadPatterns.
+// Ignore files that do not exist are skipped.
+func readIgnorePatterns(root string, domain []string, extra bool) (ps []gitignore.Pattern, err error) {
+	for _, filename := range ignoreFilenames(domain, extra) {
+		f, err := os.Open(filepath.Join(root, filepath.Join(domain...), filename))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		data, err := ioutil.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return nil, err
 		}
-		return
-	}
-	defer f.Close()
-	ps := []gitignore.Pattern{}
-	if data, err := ioutil.ReadAll(f); err == nil {
 		for _, s := range strings.Split(string(data), ignorefileEOL) {
 			if !strings.HasPrefix(s, ignorefileCommentPrefix) && len(strings.TrimSpace(s)) > 0 {
-				ps = append(ps, gitignore.ParsePattern(s, nil))
+				if filename == DockerIgnoreFilename {
+					s = dockerIgnorePattern(s)
+				}
+				ps = append(ps, gitignore.ParsePattern(s, domain))
 			}
 		}
 	}
-	m = gitignore.NewMatcher(ps)
 	return
 }
 
+// dockerIgnorePattern converts the .dockerignore pattern s to the equivalent gitignore one,
+// since .dockerignore patterns are always relative to the root directory.
+func dockerIgnorePattern(s string) string {
+	s = strings.TrimSpace(s)
+	neg := strings.HasPrefix(s, "!")
+	s = strings.TrimPrefix(s, "!")
+	s = "/" + strings.TrimPrefix(path.Clean(filepath.ToSlash(s)), "/")
+	if neg {
+		return "!" + s
+	}
+	return s
+}
+
 // initIgnoreFile writes the default ignore file if it does not exist.
 func initIgnoreFile(root string) error {
 	filename := filepath.Join(root, IgnoreFilename)
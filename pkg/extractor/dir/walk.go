@@ -9,25 +9,40 @@
 package dir
 
 import (
+	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync/atomic"
 
 	"github.com/vchain-us/vcn/pkg/bundle"
+	"gopkg.in/src-d/go-git.v4/plumbing/format/gitignore"
 )
 
-func walk(root string) (files []bundle.Descriptor, err error) {
-	files = make([]bundle.Descriptor, 0)
-	ignore, err := newIgnoreFileMatcher(root)
+func walk(root string, opts *opts) ([]bundle.Descriptor, error) {
+	entries, err := collect(root, opts.extraIgnoreFiles)
 	if err != nil {
-		return
+		return nil, err
 	}
-	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
-		// skip irregular files (e.g. dir, symlink, pipe, socket, device...)
-		if !info.Mode().IsRegular() {
-			return nil
-		}
+	return digest(root, entries, opts.workers, opts.progress)
+}
 
+// entry is a relative path within the walked directory and its file mode.
+type entry struct {
+	path string
+	mode os.FileMode
+}
+
+// collect returns the entries of all regular files, symbolic links and empty directories within root,
+// but the ones matching ignore patterns.
+func collect(root string, extraIgnoreFiles bool) (entries []entry, err error) {
+	entries = make([]entry, 0)
+	dirs := make([]entry, 0)
+	patterns := []gitignore.Pattern{}
+	ignore := gitignore.NewMatcher(patterns)
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
 		relPath, err := filepath.Rel(root, path)
 		if err != nil {
 			return err
@@ -35,23 +50,163 @@ func walk(root string) (files []bundle.Descriptor, err error) {
 		// descriptor's path must be OS agnostic
 		relPath = filepath.ToSlash(relPath)
 
+		// collect the ignore patterns of each directory, scoped to it
+		if info.IsDir() {
+			domain := []string{}
+			if relPath != "." {
+				domain = strings.Split(relPath, "/")
+			}
+			// ignored directories are not walked, so their own ignore files
+			// cannot re-include anything (as for git)
+			if relPath != "." && ignore.Match(domain, true) {
+				return filepath.SkipDir
+			}
+			ps, err := readIgnorePatterns(root, domain, extraIgnoreFiles)
+			if err != nil {
+				return err
+			}
+			if relPath != "." {
+				dirs = append(dirs, entry{relPath, info.Mode()})
+			}
+			if len(ps) > 0 {
+				patterns = append(patterns, ps...)
+				ignore = gitignore.NewMatcher(patterns)
+			}
+			return nil
+		}
+
+		// skip irregular files (e.g. pipe, socket, device...)
+		if !info.Mode().IsRegular() && info.Mode()&os.ModeSymlink == 0 {
+			return nil
+		}
+
 		// skip manifest and files matching the ignore patterns
 		if relPath == bundle.ManifestFilename || ignore.Match(strings.Split(relPath, "/"), false) {
 			return nil
 		}
 
-		file, err := os.Open(path)
+		entries = append(entries, entry{relPath, info.Mode()})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// finally, add directories not containing any other entry,
+	// deepest first since walking order is lexical
+	notEmpty := make(map[string]bool)
+	markParents := func(p string) {
+		for p = path.Dir(p); p != "." && !notEmpty[p]; p = path.Dir(p) {
+			notEmpty[p] = true
+		}
+	}
+	for _, e := range entries {
+		markParents(e.path)
+	}
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if !notEmpty[dirs[i].path] {
+			entries = append(entries, dirs[i])
+			markParents(dirs[i].path)
+		}
+	}
+	return
+}
+
+// digest returns a descriptor for each of entries (relative to root), in the same order,
+// by hashing files concurrently with up to the given number of workers (defaults to the number of CPUs).
+// If not nil, progress is called, sequentially, each time an entry has been processed.
+func digest(root string, entries []entry, workers int, progress func(done, total int)) ([]bundle.Descriptor, error) {
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(entries) {
+		workers = len(entries)
+	}
+
+	files := make([]bundle.Descriptor, len(entries))
+	queue := make(chan int)
+	results := make(chan error)
+	var failed int32
+
+	for w := 0; w < workers; w++ {
+		go func() {
+			for i := range queue {
+				// after a failure, just drain the queue
+				if atomic.LoadInt32(&failed) != 0 {
+					results <- nil
+					continue
+				}
+				results <- digestEntry(root, entries[i], &files[i])
+			}
+		}()
+	}
+	go func() {
+		for i := range entries {
+			queue <- i
+		}
+		close(queue)
+	}()
+
+	var err error
+	for n := 1; n <= len(entries); n++ {
+		if e := <-results; e != nil && err == nil {
+			err = e
+			atomic.StoreInt32(&failed, 1)
+		}
+		if err == nil && progress != nil {
+			progress(n, len(entries))
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// Descriptor returns the descriptor, having the given path, of the file named by filename,
+// exactly as it would be recorded into a manifest when walking a directory.
+func Descriptor(filename string, path string) (*bundle.Descriptor, error) {
+	info, err := os.Lstat(filename)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() && !info.IsDir() && info.Mode()&os.ModeSymlink == 0 {
+		return nil, fmt.Errorf("%s is not a regular file, a directory nor a symbolic link", filename)
+	}
+	d := &bundle.Descriptor{}
+	if err := describe(filename, entry{path, info.Mode()}, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func digestEntry(root string, e entry, d *bundle.Descriptor) error {
+	return describe(filepath.Join(root, filepath.FromSlash(e.path)), e, d)
+}
+
+func describe(filename string, e entry, d *bundle.Descriptor) error {
+	switch bundle.FileMode(e.mode) {
+	case bundle.ModeDir:
+		*d = *bundle.NewDirDescriptor(e.path)
+	case bundle.ModeSymlink:
+		target, err := os.Readlink(filename)
 		if err != nil {
 			return err
 		}
-		d, err := bundle.NewDescriptor(relPath, file)
-		file.Close()
+		// link's target must be OS agnostic too
+		*d = *bundle.NewSymlinkDescriptor(e.path, filepath.ToSlash(target))
+	default:
+		file, err := os.Open(filename)
 		if err != nil {
 			return err
 		}
-		files = append(files, *d)
-
-		return nil
-	})
-	return
+		defer file.Close()
+		dd, err := bundle.NewDescriptor(e.path, file)
+		if err != nil {
+			return err
+		}
+		dd.Mode = bundle.FileMode(e.mode)
+		*d = *dd
+	}
+	return nil
 }
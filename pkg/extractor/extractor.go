@@ -16,15 +16,26 @@ import (
 )
 
 var extractors = map[string]Extractor{}
+var multiExtractors = map[string]MultiExtractor{}
 
 // Extractor extract an api.Artifact referenced by the given uri.URI.
 type Extractor func(*uri.URI, ...Option) (*api.Artifact, error)
 
+// MultiExtractor extract all api.Artifact(s) referenced by the given uri.URI,
+// for URIs that can refer to more than one artifact (eg. a range of git commits).
+type MultiExtractor func(*uri.URI, ...Option) ([]*api.Artifact, error)
+
 // Register the Extractor e for the given scheme
 func Register(scheme string, e Extractor) {
 	extractors[scheme] = e
 }
 
+// RegisterMulti registers the MultiExtractor e for the given scheme.
+// It's used by ExtractAll() in place of the Extractor registered for the same scheme.
+func RegisterMulti(scheme string, e MultiExtractor) {
+	multiExtractors[scheme] = e
+}
+
 // Schemes returns the list of registered schemes.
 func Schemes() []string {
 	schemes := make([]string, len(extractors))
@@ -48,3 +59,22 @@ func Extract(rawURI string, options ...Option) (*api.Artifact, error) {
 	}
 	return nil, fmt.Errorf("%s scheme not yet supported", u.Scheme)
 }
+
+// ExtractAll returns all api.Artifact(s) for the given rawURI.
+// If no MultiExtractor is registered for the rawURI's scheme, it works like Extract().
+func ExtractAll(rawURI string, options ...Option) ([]*api.Artifact, error) {
+	u, err := uri.Parse(rawURI)
+	if err != nil {
+		return nil, err
+	}
+
+	if e, ok := multiExtractors[u.Scheme]; ok {
+		return e(u, options...)
+	}
+
+	a, err := Extract(rawURI, options...)
+	if err != nil || a == nil {
+		return nil, err
+	}
+	return []*api.Artifact{a}, nil
+}
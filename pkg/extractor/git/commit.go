@@ -30,7 +30,30 @@ func lastCommit(repo *git.Repository) (*object.Commit, error) {
 func digestCommit(c object.Commit) (hash string, size uint64, err error) {
 	o := &plumbing.MemoryObject{}
 	c.Encode(o)
+	return digestObject(o)
+}
+
+func digestTree(t object.Tree) (hash string, size uint64, err error) {
+	o := &plumbing.MemoryObject{}
+	t.Encode(o)
+	return digestObject(o)
+}
+
+// digest returns the hash for commit according to scheme, see Artifact().
+func digest(scheme string, commit *object.Commit) (hash string, size uint64, err error) {
+	if scheme != SchemeTree {
+		return digestCommit(*commit)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return
+	}
+	return digestTree(*tree)
+}
 
+// digestObject returns the SHA-256 of the encoded o, so that the resulting hash
+// only depends on the object content.
+func digestObject(o plumbing.EncodedObject) (hash string, size uint64, err error) {
 	reader, err := o.Reader()
 	if err != nil {
 		return
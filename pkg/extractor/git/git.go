@@ -9,10 +9,13 @@
 package git
 
 import (
+	"fmt"
 	"path/filepath"
 	"strings"
 
+	"golang.org/x/crypto/openpgp"
 	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
 
 	"github.com/vchain-us/vcn/pkg/api"
 	"github.com/vchain-us/vcn/pkg/extractor"
@@ -22,51 +25,112 @@ import (
 // Scheme for git
 const Scheme = "git"
 
-// Artifact returns a git *api.Artifact from a given u
+// SchemeTree is the scheme for git trees, the identity only depends on the source content
+const SchemeTree = "git-tree"
+
+var schemes = map[string]bool{Scheme: true, SchemeTree: true}
+
+// Artifact returns a git *api.Artifact from a given u.
+//
+// The u may be followed by "@<ref>" to target a branch, a tag or a commit other than HEAD
+// (eg. "git://path/to/repo@v1.0.0").
+// For the git scheme, the resulting hash is calculated over the commit object,
+// while for the git-tree scheme it's calculated over the commit's tree only,
+// so that the identity survives history rewrites (eg. rebases) that do not change the content.
+//
+// Commit ranges are not supported, see Artifacts().
 func Artifact(u *uri.URI, options ...extractor.Option) (*api.Artifact, error) {
 
-	if u.Scheme != Scheme {
+	if !schemes[u.Scheme] {
 		return nil, nil
 	}
 
-	path := strings.TrimPrefix(u.Opaque, "//")
-	path, err := filepath.Abs(path)
+	src, err := open(u, options...)
 	if err != nil {
 		return nil, err
 	}
 
-	repo, err := git.PlainOpen(path)
+	if isRange(src.ref) {
+		return nil, fmt.Errorf("%s refers to a range of commits, please notarize or authenticate it alone", u)
+	}
+
+	commit, tag, err := resolve(src.repo, src.ref)
 	if err != nil {
 		return nil, err
 	}
 
-	commit, err := lastCommit(repo)
+	return src.artifact(commit, tag)
+}
+
+// Artifacts returns a git *api.Artifact for each commit referenced by u.
+//
+// In addition to what Artifact() accepts, the u may be followed by "@<from>..<to>" to target
+// all commits reachable from <to> but not from <from> (like `git log <from>..<to>`),
+// either <from> or <to> can be omitted to mean HEAD.
+// Artifacts are returned from the oldest commit to the newest one.
+func Artifacts(u *uri.URI, options ...extractor.Option) ([]*api.Artifact, error) {
+
+	if !schemes[u.Scheme] {
+		return nil, nil
+	}
+
+	src, err := open(u, options...)
 	if err != nil {
 		return nil, err
 	}
 
-	hash, size, err := digestCommit(*commit)
+	if !isRange(src.ref) {
+		commit, tag, err := resolve(src.repo, src.ref)
+		if err != nil {
+			return nil, err
+		}
+		a, err := src.artifact(commit, tag)
+		if err != nil {
+			return nil, err
+		}
+		return []*api.Artifact{a}, nil
+	}
+
+	commits, err := walkRange(src.repo, src.ref)
 	if err != nil {
 		return nil, err
 	}
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("no commits found in range %s", src.ref)
+	}
 
-	// Metadata container
-	m := api.Metadata{
-		Scheme: map[string]interface{}{
-			"Commit": commit.Hash.String(),
-			"Tree":   commit.TreeHash.String(),
-			"Parents": func() []string {
-				res := make([]string, len(commit.ParentHashes))
-				for i, h := range commit.ParentHashes {
-					res[i] = h.String()
-				}
-				return res
-			}(),
-			"Author":       commit.Author,
-			"Committer":    commit.Committer,
-			"Message":      commit.Message,
-			"PGPSignature": commit.PGPSignature,
-		},
+	artifacts := make([]*api.Artifact, len(commits))
+	for i, c := range commits {
+		if artifacts[i], err = src.artifact(c, nil); err != nil {
+			return nil, err
+		}
+	}
+	return artifacts, nil
+}
+
+type source struct {
+	scheme string
+	repo   *git.Repository
+	name   string
+	ref    string
+	opts   *opts
+}
+
+func open(u *uri.URI, options ...extractor.Option) (*source, error) {
+	opts := &opts{}
+	if err := extractor.Options(options).Apply(opts); err != nil {
+		return nil, err
+	}
+
+	path, ref := splitRef(strings.TrimPrefix(u.Opaque, "//"))
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := git.PlainOpen(path)
+	if err != nil {
+		return nil, err
 	}
 
 	name := filepath.Base(path)
@@ -76,10 +140,75 @@ func Artifact(u *uri.URI, options ...extractor.Option) (*api.Artifact, error) {
 			name = urls[0]
 		}
 	}
-	name += "@" + commit.Hash.String()[:7]
+
+	return &source{
+		scheme: u.Scheme,
+		repo:   repo,
+		name:   name,
+		ref:    ref,
+		opts:   opts,
+	}, nil
+}
+
+// artifact returns the *api.Artifact for commit, and for tag if not nil.
+func (src *source) artifact(commit *object.Commit, tag *object.Tag) (*api.Artifact, error) {
+	hash, size, err := digest(src.scheme, commit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Metadata container
+	gm := map[string]interface{}{
+		"Commit": commit.Hash.String(),
+		"Tree":   commit.TreeHash.String(),
+		"Parents": func() []string {
+			res := make([]string, len(commit.ParentHashes))
+			for i, h := range commit.ParentHashes {
+				res[i] = h.String()
+			}
+			return res
+		}(),
+		"Author":       commit.Author,
+		"Committer":    commit.Committer,
+		"Message":      commit.Message,
+		"PGPSignature": commit.PGPSignature,
+	}
+	if src.opts.keyRing != "" {
+		gm["Signature"] = verifySignature(commit.PGPSignature, func() (*openpgp.Entity, error) {
+			return commit.Verify(src.opts.keyRing)
+		})
+	}
+	if isRange(src.ref) {
+		gm["Range"] = src.ref
+	} else if src.ref != "" {
+		gm["Ref"] = src.ref
+	}
+	if tag != nil {
+		tm := map[string]interface{}{
+			"Name":         tag.Name,
+			"Hash":         tag.Hash.String(),
+			"Tagger":       tag.Tagger,
+			"Message":      tag.Message,
+			"PGPSignature": tag.PGPSignature,
+		}
+		if src.opts.keyRing != "" {
+			tm["Signature"] = verifySignature(tag.PGPSignature, func() (*openpgp.Entity, error) {
+				return tag.Verify(src.opts.keyRing)
+			})
+		}
+		gm["Tag"] = tm
+	}
+	m := api.Metadata{Scheme: gm}
+
+	name := src.name
+	if src.scheme == SchemeTree {
+		name += "@tree:" + commit.TreeHash.String()[:7]
+	} else {
+		name += "@" + commit.Hash.String()[:7]
+	}
 
 	return &api.Artifact{
-		Kind:     Scheme,
+		Kind:     src.scheme,
 		Hash:     hash,
 		Size:     size,
 		Name:     name,
@@ -0,0 +1,183 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package git
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vchain-us/vcn/pkg/api"
+	"github.com/vchain-us/vcn/pkg/uri"
+	"golang.org/x/crypto/openpgp"
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+func testRepo(t *testing.T) (string, *git.Repository) {
+	dir, err := ioutil.TempDir("", "vcn-git-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, repo
+}
+
+func testCommit(t *testing.T, dir string, repo *git.Repository, content string, when time.Time, signKey *openpgp.Entity) plumbing.Hash {
+	if err := ioutil.WriteFile(filepath.Join(dir, "file.txt"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.Add("file.txt"); err != nil {
+		t.Fatal(err)
+	}
+	h, err := wt.Commit(content, &git.CommitOptions{
+		Author:  &object.Signature{Name: "vcn", Email: "vcn@vchain.us", When: when},
+		SignKey: signKey,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func extract(t *testing.T, rawURI string) *api.Artifact {
+	u, err := uri.Parse(rawURI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := Artifact(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func TestArtifactRefs(t *testing.T) {
+	when := time.Date(2019, 7, 1, 0, 0, 0, 0, time.UTC)
+	dir, repo := testRepo(t)
+	defer os.RemoveAll(dir)
+
+	first := testCommit(t, dir, repo, "first", when, nil)
+	_, err := repo.CreateTag("v1.0.0", first, &git.CreateTagOptions{
+		Tagger:  &object.Signature{Name: "vcn", Email: "vcn@vchain.us", When: when},
+		Message: "release",
+	})
+	assert.NoError(t, err)
+	_, err = repo.CreateTag("light", first, nil)
+	assert.NoError(t, err)
+	second := testCommit(t, dir, repo, "second", when.Add(time.Hour), nil)
+
+	head := extract(t, "git://"+dir)
+	assert.Equal(t, Scheme, head.Kind)
+	assert.Equal(t, second.String(), head.Metadata[Scheme].(map[string]interface{})["Commit"])
+	assert.Equal(t, filepath.Base(dir)+"@"+second.String()[:7], head.Name)
+
+	master := extract(t, "git://"+dir+"@master")
+	assert.Equal(t, head.Hash, master.Hash)
+	assert.Equal(t, "master", master.Metadata[Scheme].(map[string]interface{})["Ref"])
+
+	tagged := extract(t, "git://"+dir+"@v1.0.0")
+	gm := tagged.Metadata[Scheme].(map[string]interface{})
+	assert.Equal(t, first.String(), gm["Commit"])
+	if assert.Contains(t, gm, "Tag") {
+		tm := gm["Tag"].(map[string]interface{})
+		assert.Equal(t, "v1.0.0", tm["Name"])
+		assert.Equal(t, "release\n", tm["Message"])
+		assert.Equal(t, "", tm["PGPSignature"])
+	}
+
+	light := extract(t, "git://"+dir+"@light")
+	assert.Equal(t, tagged.Hash, light.Hash)
+	assert.NotContains(t, light.Metadata[Scheme], "Tag")
+
+	for _, ref := range []string{first.String(), first.String()[:7]} {
+		a := extract(t, "git://"+dir+"@"+ref)
+		assert.Equal(t, tagged.Hash, a.Hash, ref)
+	}
+
+	u, _ := uri.Parse("git://" + dir + "@missing")
+	_, err = Artifact(u)
+	assert.Error(t, err)
+}
+
+func TestArtifactTree(t *testing.T) {
+	when := time.Date(2019, 7, 1, 0, 0, 0, 0, time.UTC)
+	dir1, repo1 := testRepo(t)
+	defer os.RemoveAll(dir1)
+	dir2, repo2 := testRepo(t)
+	defer os.RemoveAll(dir2)
+
+	// same content, different history
+	testCommit(t, dir1, repo1, "content", when, nil)
+	testCommit(t, dir2, repo2, "content", when.Add(time.Hour), nil)
+
+	c1 := extract(t, "git://"+dir1)
+	c2 := extract(t, "git://"+dir2)
+	assert.NotEqual(t, c1.Hash, c2.Hash)
+
+	t1 := extract(t, "git-tree://"+dir1)
+	t2 := extract(t, "git-tree://"+dir2+"@master")
+	assert.Equal(t, SchemeTree, t1.Kind)
+	assert.Equal(t, t1.Hash, t2.Hash)
+	assert.NotEqual(t, c1.Hash, t1.Hash)
+	assert.Contains(t, t1.Name, "@tree:")
+}
+
+func TestArtifactsRange(t *testing.T) {
+	when := time.Date(2019, 7, 1, 0, 0, 0, 0, time.UTC)
+	dir, repo := testRepo(t)
+	defer os.RemoveAll(dir)
+
+	first := testCommit(t, dir, repo, "first", when, nil)
+	second := testCommit(t, dir, repo, "second", when.Add(time.Hour), nil)
+	third := testCommit(t, dir, repo, "third", when.Add(2*time.Hour), nil)
+
+	for _, rng := range []string{first.String()[:7] + ".." + third.String(), first.String() + "..", first.String() + "..master"} {
+		u, _ := uri.Parse("git://" + dir + "@" + rng)
+		artifacts, err := Artifacts(u)
+		assert.NoError(t, err, rng)
+		if assert.Len(t, artifacts, 2, rng) {
+			assert.Equal(t, second.String(), artifacts[0].Metadata[Scheme].(map[string]interface{})["Commit"])
+			assert.Equal(t, third.String(), artifacts[1].Metadata[Scheme].(map[string]interface{})["Commit"])
+			assert.Equal(t, rng, artifacts[0].Metadata[Scheme].(map[string]interface{})["Range"])
+
+			// same identity as the single commit
+			assert.Equal(t, extract(t, "git://"+dir+"@"+second.String()).Hash, artifacts[0].Hash)
+		}
+
+		_, err = Artifact(u)
+		assert.Error(t, err, rng)
+	}
+
+	// single commit
+	u, _ := uri.Parse("git://" + dir)
+	artifacts, err := Artifacts(u)
+	assert.NoError(t, err)
+	assert.Len(t, artifacts, 1)
+
+	// empty range
+	u, _ = uri.Parse("git://" + dir + "@" + third.String() + "..")
+	_, err = Artifacts(u)
+	assert.Error(t, err)
+
+	u, _ = uri.Parse("git://" + dir + "@" + first.String() + "..." + third.String())
+	_, err = Artifacts(u)
+	assert.Error(t, err)
+}
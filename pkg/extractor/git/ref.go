@@ -0,0 +1,177 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package git
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+var shortHashRegExp = regexp.MustCompile("^[0-9a-f]{4,39}$")
+
+// splitRef splits s into the repository path and an optional ref, separated by the last "@".
+// If s is an existing path, no ref is assumed.
+func splitRef(s string) (path string, ref string) {
+	if _, err := os.Stat(s); err == nil {
+		return s, ""
+	}
+	if i := strings.LastIndex(s, "@"); i >= 0 {
+		return s[:i], s[i+1:]
+	}
+	return s, ""
+}
+
+// resolve returns the commit pointed by ref (HEAD if empty), which can be a branch, a tag,
+// or a (possibly abbreviated) commit hash.
+// When ref refers to an annotated tag, the tag object is returned too.
+func resolve(repo *git.Repository, ref string) (*object.Commit, *object.Tag, error) {
+	if ref == "" {
+		c, err := lastCommit(repo)
+		return c, nil, err
+	}
+
+	if tag, err := annotatedTag(repo, ref); tag != nil || err != nil {
+		if err != nil {
+			return nil, nil, err
+		}
+		c, err := tag.Commit()
+		if err != nil {
+			return nil, nil, fmt.Errorf("tag %s does not point to a commit: %s", ref, err)
+		}
+		return c, tag, nil
+	}
+
+	h, err := repo.ResolveRevision(plumbing.Revision(ref))
+	if err != nil && shortHashRegExp.MatchString(ref) {
+		h, err = resolveShortHash(repo, ref)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot resolve %s: %s", ref, err)
+	}
+
+	c, err := repo.CommitObject(*h)
+	return c, nil, err
+}
+
+// annotatedTag returns the tag object referenced by ref, if any.
+func annotatedTag(repo *git.Repository, ref string) (*object.Tag, error) {
+	if h := plumbing.NewHash(ref); !h.IsZero() && len(ref) == 40 {
+		if tag, err := repo.TagObject(h); err == nil {
+			return tag, nil
+		}
+		return nil, nil
+	}
+
+	for _, rule := range append([]string{"%s"}, plumbing.RefRevParseRules...) {
+		r, err := repo.Reference(plumbing.ReferenceName(fmt.Sprintf(rule, ref)), true)
+		if err != nil {
+			continue
+		}
+		if tag, err := repo.TagObject(r.Hash()); err == nil {
+			return tag, nil
+		}
+		return nil, nil
+	}
+	return nil, nil
+}
+
+// resolveShortHash returns the hash of the only commit starting with prefix.
+func resolveShortHash(repo *git.Repository, prefix string) (*plumbing.Hash, error) {
+	iter, err := repo.CommitObjects()
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var found *plumbing.Hash
+	err = iter.ForEach(func(c *object.Commit) error {
+		if !strings.HasPrefix(c.Hash.String(), prefix) {
+			return nil
+		}
+		if found != nil {
+			return fmt.Errorf("short hash %s is ambiguous", prefix)
+		}
+		h := c.Hash
+		found = &h
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, plumbing.ErrReferenceNotFound
+	}
+	return found, nil
+}
+
+// isRange returns true if ref is a commit range (ie. "<from>..<to>").
+func isRange(ref string) bool {
+	return strings.Contains(ref, "..")
+}
+
+// walkRange returns all commits reachable from <to> but not from <from>, from the oldest to the newest,
+// where the range is given as "<from>..<to>" and an empty side means HEAD.
+func walkRange(repo *git.Repository, rng string) ([]*object.Commit, error) {
+	if strings.Contains(rng, "...") {
+		return nil, fmt.Errorf("symmetric difference ranges are not supported: %s", rng)
+	}
+	parts := strings.SplitN(rng, "..", 2)
+
+	from, _, err := resolve(repo, parts[0])
+	if err != nil {
+		return nil, err
+	}
+	to, _, err := resolve(repo, parts[1])
+	if err != nil {
+		return nil, err
+	}
+
+	// all commits reachable from <from> are excluded
+	excluded := map[plumbing.Hash]bool{}
+	iter, err := repo.Log(&git.LogOptions{From: from.Hash})
+	if err != nil {
+		return nil, err
+	}
+	err = iter.ForEach(func(c *object.Commit) error {
+		excluded[c.Hash] = true
+		return nil
+	})
+	iter.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	commits := []*object.Commit{}
+	iter, err = repo.Log(&git.LogOptions{From: to.Hash})
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+	err = iter.ForEach(func(c *object.Commit) error {
+		if !excluded[c.Hash] {
+			commits = append(commits, c)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// oldest first
+	for i, j := 0, len(commits)-1; i < j; i, j = i+1, j-1 {
+		commits[i], commits[j] = commits[j], commits[i]
+	}
+	return commits, nil
+}
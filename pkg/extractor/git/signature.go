@@ -0,0 +1,137 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package git
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vchain-us/vcn/pkg/api"
+	"github.com/vchain-us/vcn/pkg/extractor"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+	"golang.org/x/crypto/openpgp/packet"
+)
+
+// Signature is the result of the verification of a commit's (or tag's) PGP signature.
+type Signature struct {
+	Verified    bool
+	KeyID       string `json:",omitempty"`
+	Fingerprint string `json:",omitempty"`
+	Signer      string `json:",omitempty"`
+	Error       string `json:",omitempty"`
+}
+
+type opts struct {
+	keyRing string
+}
+
+// WithKeyRing returns a functional option to instruct the git's extractor to verify the PGP signature
+// of the commit (and of the annotated tag, if any) against the given armored keyring.
+// The verification result is stored into the artifact's metadata, see CommitSignature().
+func WithKeyRing(armoredKeyRing string) extractor.Option {
+	return func(o interface{}) error {
+		if o, ok := o.(*opts); ok {
+			o.keyRing = armoredKeyRing
+		}
+		return nil
+	}
+}
+
+// CommitSignature returns the verification result of the commit's PGP signature for a,
+// or nil if a is not a git artifact or it was extracted without a keyring.
+func CommitSignature(a *api.Artifact) *Signature {
+	if a == nil || !schemes[a.Kind] {
+		return nil
+	}
+	gm, ok := a.Metadata[Scheme].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	s, _ := gm["Signature"].(*Signature)
+	return s
+}
+
+// verifySignature checks armoredSignature by calling verifyFn, then it fills the returned Signature
+// with the signing key's details.
+func verifySignature(armoredSignature string, verifyFn func() (*openpgp.Entity, error)) *Signature {
+	if armoredSignature == "" {
+		return &Signature{Error: "no signature"}
+	}
+
+	s := &Signature{}
+	keyID, err := issuerKeyID(armoredSignature)
+	if err != nil {
+		s.Error = err.Error()
+		return s
+	}
+	s.KeyID = fmt.Sprintf("%016X", keyID)
+
+	entity, err := verifyFn()
+	if err != nil {
+		s.Error = err.Error()
+		return s
+	}
+
+	s.Verified = true
+	if entity.PrimaryKey.KeyId == keyID {
+		s.Fingerprint = fmt.Sprintf("%X", entity.PrimaryKey.Fingerprint)
+	}
+	for _, k := range entity.Subkeys {
+		if k.PublicKey.KeyId == keyID {
+			s.Fingerprint = fmt.Sprintf("%X", k.PublicKey.Fingerprint)
+		}
+	}
+	if id := primaryIdentity(entity); id != nil {
+		s.Signer = id.Name
+	}
+	return s
+}
+
+// primaryIdentity returns the identity of entity marked as primary, or any identity if none is marked,
+// like openpgp's unexported Entity.primaryIdentity does.
+// Since identities are held by a map, ties are broken by name so that the result is stable.
+func primaryIdentity(entity *openpgp.Entity) *openpgp.Identity {
+	var first, primary *openpgp.Identity
+	for _, id := range entity.Identities {
+		if first == nil || id.Name < first.Name {
+			first = id
+		}
+		if id.SelfSignature != nil && id.SelfSignature.IsPrimaryId != nil && *id.SelfSignature.IsPrimaryId {
+			if primary == nil || id.Name < primary.Name {
+				primary = id
+			}
+		}
+	}
+	if primary != nil {
+		return primary
+	}
+	return first
+}
+
+// issuerKeyID returns the ID of the key that made armoredSignature.
+func issuerKeyID(armoredSignature string) (uint64, error) {
+	block, err := armor.Decode(strings.NewReader(armoredSignature))
+	if err != nil {
+		return 0, fmt.Errorf("invalid signature: %s", err)
+	}
+	p, err := packet.Read(block.Body)
+	if err != nil {
+		return 0, fmt.Errorf("invalid signature: %s", err)
+	}
+	switch sig := p.(type) {
+	case *packet.Signature:
+		if sig.IssuerKeyId != nil {
+			return *sig.IssuerKeyId, nil
+		}
+	case *packet.SignatureV3:
+		return sig.IssuerKeyId, nil
+	}
+	return 0, fmt.Errorf("invalid signature: no issuer key ID")
+}
@@ -0,0 +1,122 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vchain-us/vcn/pkg/uri"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+	"golang.org/x/crypto/openpgp/packet"
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+func testKey(t *testing.T, name string) (*openpgp.Entity, string) {
+	e, err := openpgp.NewEntity(name, "", name+"@vchain.us", &packet.Config{RSABits: 1024})
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	w, err := armor.Encode(buf, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Serialize(w); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	return e, buf.String()
+}
+
+func TestSignature(t *testing.T) {
+	when := time.Date(2019, 7, 1, 0, 0, 0, 0, time.UTC)
+	dir, repo := testRepo(t)
+	defer os.RemoveAll(dir)
+
+	key, keyRing := testKey(t, "alice")
+	_, otherKeyRing := testKey(t, "bob")
+
+	unsigned := testCommit(t, dir, repo, "unsigned", when, nil)
+	signed := testCommit(t, dir, repo, "signed", when, key)
+	_, err := repo.CreateTag("v1.0.0", signed, &git.CreateTagOptions{
+		Tagger:  &object.Signature{Name: "alice", Email: "alice@vchain.us", When: when},
+		Message: "release",
+		SignKey: key,
+	})
+	assert.NoError(t, err)
+
+	u, _ := uri.Parse("git://" + dir + "@v1.0.0")
+
+	// no keyring, no verification
+	a, err := Artifact(u)
+	assert.NoError(t, err)
+	assert.Nil(t, CommitSignature(a))
+
+	a, err = Artifact(u, WithKeyRing(keyRing))
+	assert.NoError(t, err)
+	s := CommitSignature(a)
+	if assert.NotNil(t, s) {
+		assert.True(t, s.Verified)
+		assert.Equal(t, fmt.Sprintf("%016X", key.PrimaryKey.KeyId), s.KeyID)
+		assert.Equal(t, fmt.Sprintf("%X", key.PrimaryKey.Fingerprint), s.Fingerprint)
+		assert.Equal(t, "alice <alice@vchain.us>", s.Signer)
+		assert.Empty(t, s.Error)
+	}
+	ts := a.Metadata[Scheme].(map[string]interface{})["Tag"].(map[string]interface{})["Signature"].(*Signature)
+	assert.True(t, ts.Verified)
+	assert.Equal(t, s.KeyID, ts.KeyID)
+
+	// signed by a key not in the keyring
+	a, err = Artifact(u, WithKeyRing(otherKeyRing))
+	assert.NoError(t, err)
+	s = CommitSignature(a)
+	if assert.NotNil(t, s) {
+		assert.False(t, s.Verified)
+		assert.Equal(t, fmt.Sprintf("%016X", key.PrimaryKey.KeyId), s.KeyID)
+		assert.NotEmpty(t, s.Error)
+	}
+
+	// unsigned
+	u, _ = uri.Parse("git://" + dir + "@" + unsigned.String())
+	a, err = Artifact(u, WithKeyRing(keyRing))
+	assert.NoError(t, err)
+	s = CommitSignature(a)
+	if assert.NotNil(t, s) {
+		assert.False(t, s.Verified)
+		assert.Equal(t, "no signature", s.Error)
+	}
+}
+
+func TestPrimaryIdentity(t *testing.T) {
+	e, _ := testKey(t, "zed")
+	assert.Equal(t, "zed <zed@vchain.us>", primaryIdentity(e).Name)
+
+	// another identity, not primary, sorting first by name
+	primary := false
+	uid := packet.NewUserId("alice", "", "alice@vchain.us")
+	e.Identities[uid.Id] = &openpgp.Identity{
+		Name:          uid.Id,
+		UserId:        uid,
+		SelfSignature: &packet.Signature{IsPrimaryId: &primary},
+	}
+	assert.Equal(t, "zed <zed@vchain.us>", primaryIdentity(e).Name)
+
+	// no primary identity
+	delete(e.Identities, "zed <zed@vchain.us>")
+	assert.Equal(t, "alice <alice@vchain.us>", primaryIdentity(e).Name)
+
+	assert.Nil(t, primaryIdentity(&openpgp.Entity{}))
+}
@@ -0,0 +1,479 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package oci
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+
+	digest "github.com/opencontainers/go-digest"
+	"github.com/vchain-us/vcn/pkg/extractor/archive"
+)
+
+const (
+	ociIndexFile       = "index.json"
+	dockerManifestFile = "manifest.json"
+
+	mediaTypeOCIIndex        = "application/vnd.oci.image.index.v1+json"
+	mediaTypeDockerList      = "application/vnd.docker.distribution.manifest.list.v2+json"
+	annotationRefName        = "org.opencontainers.image.ref.name"
+	annotationContainerdName = "io.containerd.image.name"
+)
+
+// source provides read access to the files of a layout.
+type source interface {
+	// readFiles returns the content of the named files, missing files are not included.
+	readFiles(names ...string) (map[string][]byte, error)
+
+	// digests returns the SHA-256 digests of the named files, without keeping them in memory.
+	// Missing files are not included.
+	digests(names ...string) (map[string]digest.Digest, error)
+}
+
+type dirSource string
+
+func (d dirSource) open(name string) (*os.File, error) {
+	return os.Open(filepath.Join(string(d), filepath.FromSlash(name)))
+}
+
+func (d dirSource) readFiles(names ...string) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	for _, name := range names {
+		f, err := d.open(name)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		b, err := ioutil.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		files[name] = b
+	}
+	return files, nil
+}
+
+func (d dirSource) digests(names ...string) (map[string]digest.Digest, error) {
+	digests := map[string]digest.Digest{}
+	for _, name := range names {
+		f, err := d.open(name)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		dgst, err := digest.SHA256.FromReader(f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		digests[name] = dgst
+	}
+	return digests, nil
+}
+
+// tarSource is a (compressed) tarball, that is scanned once per call.
+type tarSource string
+
+// walk calls fn for each regular file within t whose name is wanted, until all of them are found.
+func (t tarSource) walk(names []string, fn func(name string, r io.Reader) error) error {
+	wanted := map[string]bool{}
+	for _, name := range names {
+		wanted[path.Clean(name)] = true
+	}
+	if len(wanted) == 0 {
+		return nil
+	}
+
+	f, err := os.Open(string(t))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r, err := archive.Decompress(f)
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(r)
+	for len(wanted) > 0 {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("cannot read %s: %s", string(t), err)
+		}
+		name := path.Clean(hdr.Name)
+		if hdr.Typeflag != tar.TypeReg && hdr.Typeflag != tar.TypeRegA || !wanted[name] {
+			continue
+		}
+		delete(wanted, name)
+		if err := fn(name, tr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t tarSource) readFiles(names ...string) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	err := t.walk(names, func(name string, r io.Reader) (err error) {
+		files[name], err = ioutil.ReadAll(r)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return lookup(files, names), nil
+}
+
+func (t tarSource) digests(names ...string) (map[string]digest.Digest, error) {
+	digests := map[string]digest.Digest{}
+	err := t.walk(names, func(name string, r io.Reader) (err error) {
+		digests[name], err = digest.SHA256.FromReader(r)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	found := map[string]digest.Digest{}
+	for _, name := range names {
+		if d, ok := digests[path.Clean(name)]; ok {
+			found[name] = d
+		}
+	}
+	return found, nil
+}
+
+// lookup returns files keyed by names as passed, rather than cleaned.
+func lookup(files map[string][]byte, names []string) map[string][]byte {
+	found := map[string][]byte{}
+	for _, name := range names {
+		if b, ok := files[path.Clean(name)]; ok {
+			found[name] = b
+		}
+	}
+	return found
+}
+
+// layout reads images from a source, keeping in memory only the files needed to describe them.
+// Layers are never kept in memory, the ones of the selected image are only hashed (see verifyLayers).
+type layout struct {
+	source
+	blobs map[digest.Digest][]byte
+}
+
+func openSource(filename string) (*layout, error) {
+	stat, err := os.Stat(filename)
+	if err != nil {
+		return nil, err
+	}
+	if stat.IsDir() {
+		return &layout{source: dirSource(filename)}, nil
+	}
+	return &layout{source: tarSource(filename)}, nil
+}
+
+// images returns all images found within l.
+// The docker manifest is preferred over the OCI index, when both are present.
+func (l *layout) images() (images []image, err error) {
+	files, err := l.readFiles(dockerManifestFile, ociIndexFile)
+	if err != nil {
+		return nil, err
+	}
+	if b, ok := files[dockerManifestFile]; ok {
+		images, err = l.dockerImages(b)
+	} else if b, ok := files[ociIndexFile]; ok {
+		images, err = l.ociImages(b)
+	} else {
+		return nil, fmt.Errorf("neither %s nor %s found", ociIndexFile, dockerManifestFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return merge(images), nil
+}
+
+// merge merges images having the same ID, so that each image is listed once with all its tags.
+func merge(images []image) []image {
+	merged := []image{}
+	index := map[string]int{}
+	for _, i := range images {
+		j, ok := index[i.ID]
+		if !ok {
+			index[i.ID] = len(merged)
+			merged = append(merged, i)
+			continue
+		}
+		for _, t := range i.RepoTags {
+			found := false
+			for _, tt := range merged[j].RepoTags {
+				if t == tt {
+					found = true
+					break
+				}
+			}
+			if !found {
+				merged[j].RepoTags = append(merged[j].RepoTags, t)
+			}
+		}
+	}
+	return merged
+}
+
+type config struct {
+	Created      string `json:"created"`
+	Author       string `json:"author"`
+	Architecture string `json:"architecture"`
+	Os           string `json:"os"`
+	RootFS       struct {
+		DiffIDs []string `json:"diff_ids"`
+	} `json:"rootfs"`
+}
+
+// newImage returns the image described by the config blob b.
+// The image ID is the digest of the config, it's checked against expected if not empty.
+func newImage(b []byte, expected digest.Digest, repoTags []string) (*image, error) {
+	id := digest.SHA256.FromBytes(b)
+	if expected != "" && expected != id {
+		return nil, fmt.Errorf("config digest mismatch: expected %s, got %s", expected, id)
+	}
+	c := config{}
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("invalid image config %s: %s", id, err)
+	}
+	if repoTags == nil {
+		repoTags = []string{}
+	}
+	layers := c.RootFS.DiffIDs
+	if layers == nil {
+		layers = []string{}
+	}
+	return &image{
+		ID:           id.String(),
+		RepoTags:     repoTags,
+		Created:      c.Created,
+		Author:       c.Author,
+		Architecture: c.Architecture,
+		Os:           c.Os,
+		Layers:       layers,
+	}, nil
+}
+
+// dockerImages returns the images listed by the `docker save` manifest b.
+func (l *layout) dockerImages(b []byte) ([]image, error) {
+	entries := []struct {
+		Config   string
+		RepoTags []string
+		Layers   []string
+	}{}
+	if err := json.Unmarshal(b, &entries); err != nil {
+		return nil, fmt.Errorf("invalid %s: %s", dockerManifestFile, err)
+	}
+	configs := make([]string, len(entries))
+	for i, e := range entries {
+		configs[i] = e.Config
+	}
+	files, err := l.readFiles(configs...)
+	if err != nil {
+		return nil, err
+	}
+
+	images := make([]image, len(entries))
+	for i, e := range entries {
+		cb, ok := files[e.Config]
+		if !ok {
+			return nil, fmt.Errorf("cannot read image config %s: %s", e.Config, os.ErrNotExist)
+		}
+		img, err := newImage(cb, "", e.RepoTags)
+		if err != nil {
+			return nil, err
+		}
+		// layers are uncompressed, so their digests are the config's diff IDs
+		img.layerFiles = e.Layers
+		for _, id := range img.Layers {
+			img.layerDigests = append(img.layerDigests, digest.Digest(id))
+		}
+		images[i] = *img
+	}
+	return images, nil
+}
+
+type descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      digest.Digest     `json:"digest"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+func (d descriptor) refs() []string {
+	refs := []string{}
+	for _, k := range []string{annotationContainerdName, annotationRefName} {
+		if v := d.Annotations[k]; v != "" {
+			refs = append(refs, v)
+		}
+	}
+	return refs
+}
+
+func blobFile(d digest.Digest) string {
+	return path.Join("blobs", d.Algorithm().String(), d.Hex())
+}
+
+// load reads the blobs of the indexes, manifests and configs reachable from index b,
+// one nesting level at a time, so that a tarball is scanned once per level.
+func (l *layout) load(b []byte) error {
+	l.blobs = map[digest.Digest][]byte{}
+	for next := [][]byte{b}; len(next) > 0; {
+		wanted := []string{}
+		digests := map[string]digest.Digest{}
+		configs := map[digest.Digest]bool{}
+		want := func(d descriptor, config bool) {
+			if _, ok := l.blobs[d.Digest]; ok || d.Digest.Validate() != nil {
+				return
+			}
+			l.blobs[d.Digest] = nil
+			wanted = append(wanted, blobFile(d.Digest))
+			digests[blobFile(d.Digest)] = d.Digest
+			configs[d.Digest] = config
+		}
+		for _, b := range next {
+			// errors are reported later, when decoding the blob
+			node := struct {
+				Manifests []descriptor `json:"manifests"`
+				Config    *descriptor  `json:"config"`
+			}{}
+			json.Unmarshal(b, &node)
+			for _, d := range node.Manifests {
+				want(d, false)
+			}
+			if node.Config != nil {
+				want(*node.Config, true)
+			}
+		}
+
+		files, err := l.readFiles(wanted...)
+		if err != nil {
+			return err
+		}
+		next = nil
+		for name, b := range files {
+			d := digests[name]
+			l.blobs[d] = b
+			if !configs[d] {
+				next = append(next, b)
+			}
+		}
+	}
+	return nil
+}
+
+// blob returns the content of the blob for d, checking its digest.
+func (l *layout) blob(d descriptor) ([]byte, error) {
+	if err := d.Digest.Validate(); err != nil {
+		return nil, err
+	}
+	b := l.blobs[d.Digest]
+	if b == nil {
+		return nil, fmt.Errorf("cannot read blob %s: %s", d.Digest, os.ErrNotExist)
+	}
+	if d.Digest.Algorithm().FromBytes(b) != d.Digest {
+		return nil, fmt.Errorf("blob digest mismatch: %s", d.Digest)
+	}
+	return b, nil
+}
+
+// ociImages returns the images referenced by the OCI index b,
+// nested indexes (eg. multi-platform images) included.
+func (l *layout) ociImages(b []byte) ([]image, error) {
+	if err := l.load(b); err != nil {
+		return nil, err
+	}
+	return l.indexImages(b, nil)
+}
+
+func (l *layout) indexImages(b []byte, refs []string) ([]image, error) {
+	index := struct {
+		Manifests []descriptor `json:"manifests"`
+	}{}
+	if err := json.Unmarshal(b, &index); err != nil {
+		return nil, fmt.Errorf("invalid image index: %s", err)
+	}
+
+	images := []image{}
+	for _, d := range index.Manifests {
+		dRefs := append(d.refs(), refs...)
+		mb, err := l.blob(d)
+		if err != nil {
+			return nil, err
+		}
+		if d.MediaType == mediaTypeOCIIndex || d.MediaType == mediaTypeDockerList {
+			nested, err := l.indexImages(mb, dRefs)
+			if err != nil {
+				return nil, err
+			}
+			images = append(images, nested...)
+			continue
+		}
+
+		manifest := struct {
+			Config descriptor   `json:"config"`
+			Layers []descriptor `json:"layers"`
+		}{}
+		if err := json.Unmarshal(mb, &manifest); err != nil {
+			return nil, fmt.Errorf("invalid image manifest %s: %s", d.Digest, err)
+		}
+		cb, err := l.blob(manifest.Config)
+		if err != nil {
+			return nil, err
+		}
+		img, err := newImage(cb, manifest.Config.Digest, dRefs)
+		if err != nil {
+			return nil, err
+		}
+		for _, layer := range manifest.Layers {
+			img.layerFiles = append(img.layerFiles, blobFile(layer.Digest))
+			img.layerDigests = append(img.layerDigests, layer.Digest)
+		}
+		images = append(images, *img)
+	}
+	return images, nil
+}
+
+// verifyLayers checks that the layers of i are present and match their digests.
+// Layers are hashed while being read, so they are never kept in memory.
+func (l *layout) verifyLayers(i *image) error {
+	if len(i.layerFiles) != len(i.layerDigests) {
+		return fmt.Errorf("the layers of image %s do not match its config", i.ID)
+	}
+	digests, err := l.digests(i.layerFiles...)
+	if err != nil {
+		return err
+	}
+	for j, name := range i.layerFiles {
+		d, ok := digests[name]
+		if !ok {
+			return fmt.Errorf("cannot read layer %s: %s", name, os.ErrNotExist)
+		}
+		if d != i.layerDigests[j] {
+			return fmt.Errorf("layer digest mismatch: %s", i.layerDigests[j])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package oci
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	digest "github.com/opencontainers/go-digest"
+	"github.com/vchain-us/vcn/pkg/api"
+	"github.com/vchain-us/vcn/pkg/extractor"
+	"github.com/vchain-us/vcn/pkg/uri"
+)
+
+// Scheme for OCI image layouts and `docker save` tarballs
+const Scheme = "oci"
+
+// Artifact returns an image *api.Artifact from a given u.
+//
+// The u must point to an OCI image layout or to a `docker save` output, either as directory or
+// tarball (optionally gzip or bzip2 compressed), and it may be followed by ":<ref>" to select an image by
+// its tag or name when multiple images are present (eg. "oci://image.tar:alpine:3.10").
+// The resulting hash is the image ID, the same returned by `docker inspect`.
+func Artifact(u *uri.URI, options ...extractor.Option) (*api.Artifact, error) {
+
+	if u.Scheme != Scheme {
+		return nil, nil
+	}
+
+	path, ref, err := splitRef(strings.TrimPrefix(u.Opaque, "//"))
+	if err != nil {
+		return nil, err
+	}
+
+	src, err := openSource(path)
+	if err != nil {
+		return nil, err
+	}
+
+	images, err := src.images()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read image from %s: %s", path, err)
+	}
+
+	i, err := selectImage(images, ref)
+	if err != nil {
+		return nil, err
+	}
+	if err := src.verifyLayers(i); err != nil {
+		return nil, fmt.Errorf("cannot read image from %s: %s", path, err)
+	}
+
+	m := api.Metadata{
+		"architecture": i.Architecture,
+		"platform":     i.Os,
+	}
+
+	if version := i.inferVer(); version != "" {
+		m["version"] = version
+	}
+
+	m[Scheme] = i
+	return &api.Artifact{
+		Kind:     Scheme,
+		Name:     Scheme + "://" + i.name(),
+		Hash:     i.hash(),
+		Metadata: m,
+	}, nil
+}
+
+// splitRef splits s into the path of an existing file or directory and an optional ref.
+func splitRef(s string) (path string, ref string, err error) {
+	path = s
+	for i := 0; ; i++ {
+		if _, err = os.Stat(path); err == nil {
+			path, err = filepath.Abs(path)
+			return
+		}
+		j := strings.Index(s[i:], ":")
+		if j < 0 {
+			break
+		}
+		i += j
+		path, ref = s[:i], s[i+1:]
+	}
+	_, err = os.Stat(s)
+	return "", "", err
+}
+
+type image struct {
+	ID           string   `json:"Id"`
+	RepoTags     []string `json:"RepoTags"`
+	Created      string   `json:"Created"`
+	Author       string   `json:"Author"`
+	Architecture string   `json:"Architecture"`
+	Os           string   `json:"Os"`
+	Layers       []string `json:"Layers"`
+
+	// files and digests of the layers within the layout (see layout.verifyLayers)
+	layerFiles   []string
+	layerDigests []digest.Digest
+}
+
+func (i image) hash() string {
+	return strings.TrimPrefix(i.ID, "sha256:")
+}
+
+func (i image) name() string {
+	if len(i.RepoTags) > 0 {
+		return i.RepoTags[0]
+	}
+	return i.hash()
+}
+
+func (i image) inferVer() string {
+	if len(i.RepoTags) > 0 {
+		name := i.RepoTags[0]
+		if j := strings.LastIndex(name, ":"); j > strings.LastIndex(name, "/") {
+			if tag := name[j+1:]; tag != "latest" {
+				return tag
+			}
+		}
+	}
+
+	return ""
+}
+
+// selectImage returns the image matching ref, or the only one available if ref is empty.
+func selectImage(images []image, ref string) (*image, error) {
+	candidates := []image{}
+	for _, i := range images {
+		if ref == "" || i.hash() == strings.TrimPrefix(ref, "sha256:") {
+			candidates = append(candidates, i)
+			continue
+		}
+		for _, t := range i.RepoTags {
+			if t == ref {
+				candidates = append(candidates, i)
+				break
+			}
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		if ref != "" {
+			return nil, fmt.Errorf("no image matching %s found", ref)
+		}
+		return nil, fmt.Errorf("no image found")
+	case 1:
+		return &candidates[0], nil
+	}
+
+	// variants of the same multi-platform image, prefer the current platform
+	if variants(candidates) {
+		for _, i := range candidates {
+			if i.Os == runtime.GOOS && i.Architecture == runtime.GOARCH {
+				return &i, nil
+			}
+		}
+	}
+
+	refs := []string{}
+	for _, i := range candidates {
+		refs = append(refs, i.name())
+	}
+	return nil, fmt.Errorf("multiple images found, please select one of: %s", strings.Join(refs, ", "))
+}
+
+// variants returns true if all images have the same name (ie. are platform variants of the same image).
+func variants(images []image) bool {
+	for _, i := range images {
+		if i.name() != images[0].name() {
+			return false
+		}
+	}
+	return true
+}
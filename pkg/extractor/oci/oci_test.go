@@ -0,0 +1,236 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package oci
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	digest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/vchain-us/vcn/pkg/uri"
+)
+
+const testLayer = "layer content"
+
+var testConfig = fmt.Sprintf(
+	`{"architecture":"amd64","os":"linux","created":"2019-07-11T22:20:52Z","rootfs":{"type":"layers","diff_ids":["%s"]}}`,
+	digest.SHA256.FromString(testLayer),
+)
+
+func writeFiles(t *testing.T, root string, files map[string][]byte) {
+	for name, b := range files {
+		filename := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filename, b, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func writeTarball(t *testing.T, filename string, files map[string][]byte) {
+	f, err := os.Create(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gw := gzip.NewWriter(f)
+	defer gw.Close()
+	tw := tar.NewWriter(gw)
+	defer tw.Close()
+	for name, b := range files {
+		tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(b))})
+		tw.Write(b)
+	}
+}
+
+func blob(files map[string][]byte, b []byte) digest.Digest {
+	d := digest.SHA256.FromBytes(b)
+	files["blobs/sha256/"+d.Hex()] = b
+	return d
+}
+
+func ociLayout(t *testing.T, tags ...string) (map[string][]byte, digest.Digest) {
+	files := map[string][]byte{
+		"oci-layout": []byte(`{"imageLayoutVersion":"1.0.0"}`),
+	}
+	configDigest := blob(files, []byte(testConfig))
+	layerDigest := blob(files, []byte(testLayer))
+	manifest, _ := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"config": map[string]interface{}{
+			"mediaType": "application/vnd.oci.image.config.v1+json",
+			"digest":    configDigest,
+			"size":      len(testConfig),
+		},
+		"layers": []interface{}{
+			map[string]interface{}{
+				"mediaType": "application/vnd.oci.image.layer.v1.tar",
+				"digest":    layerDigest,
+				"size":      len(testLayer),
+			},
+		},
+	})
+	manifestDigest := blob(files, manifest)
+	manifests := []interface{}{}
+	for _, tag := range tags {
+		manifests = append(manifests, map[string]interface{}{
+			"mediaType":   "application/vnd.oci.image.manifest.v1+json",
+			"digest":      manifestDigest,
+			"size":        len(manifest),
+			"annotations": map[string]string{annotationRefName: tag},
+		})
+	}
+	files[ociIndexFile], _ = json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"manifests":     manifests,
+	})
+	return files, configDigest
+}
+
+func TestOCILayout(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "vcn-oci")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	files, id := ociLayout(t, "alpine:3.10", "alpine:latest")
+
+	// directory
+	layoutDir := filepath.Join(tmpDir, "layout")
+	writeFiles(t, layoutDir, files)
+	u, _ := uri.Parse("oci://" + layoutDir)
+	a, err := Artifact(u)
+	assert.NoError(t, err)
+	assert.NotNil(t, a)
+	assert.Equal(t, Scheme, a.Kind)
+	assert.Equal(t, id.Hex(), a.Hash)
+	assert.Equal(t, "oci://alpine:3.10", a.Name)
+	assert.Equal(t, "3.10", a.Metadata["version"])
+	assert.Equal(t, "amd64", a.Metadata["architecture"])
+	assert.Equal(t, "linux", a.Metadata["platform"])
+	i := a.Metadata[Scheme].(*image)
+	assert.Equal(t, []string{"alpine:3.10", "alpine:latest"}, i.RepoTags)
+	assert.Len(t, i.Layers, 1)
+
+	// tarball, with ref
+	tarball := filepath.Join(tmpDir, "layout.tar.gz")
+	writeTarball(t, tarball, files)
+	u, _ = uri.Parse("oci://" + tarball + ":alpine:latest")
+	a, err = Artifact(u)
+	assert.NoError(t, err)
+	assert.Equal(t, id.Hex(), a.Hash)
+
+	u, _ = uri.Parse("oci://" + tarball + ":not-existing")
+	a, err = Artifact(u)
+	assert.Error(t, err)
+	assert.Nil(t, a)
+
+	// tampered layer
+	layer := "blobs/sha256/" + digest.SHA256.FromString(testLayer).Hex()
+	files[layer] = []byte("tampered")
+	writeTarball(t, tarball, files)
+	u, _ = uri.Parse("oci://" + tarball)
+	a, err = Artifact(u)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "layer digest mismatch")
+	}
+	assert.Nil(t, a)
+
+	// missing layer
+	delete(files, layer)
+	writeTarball(t, tarball, files)
+	a, err = Artifact(u)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "cannot read layer")
+	}
+	assert.Nil(t, a)
+
+	// tampered config
+	files["blobs/sha256/"+id.Hex()] = []byte(`{}`)
+	tampered := filepath.Join(tmpDir, "tampered")
+	writeFiles(t, tampered, files)
+	u, _ = uri.Parse("oci://" + tampered)
+	a, err = Artifact(u)
+	assert.Error(t, err)
+	assert.Nil(t, a)
+}
+
+func TestDockerSave(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "vcn-oci")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	id := digest.SHA256.FromBytes([]byte(testConfig))
+	other := `{"architecture":"arm64","os":"linux"}`
+	otherID := digest.SHA256.FromBytes([]byte(other))
+	files := map[string][]byte{
+		"abc/layer.tar":         []byte(testLayer),
+		id.Hex() + ".json":      []byte(testConfig),
+		otherID.Hex() + ".json": []byte(other),
+		dockerManifestFile: []byte(fmt.Sprintf(
+			`[{"Config":"%s.json","RepoTags":["hello-world:latest"],"Layers":["abc/layer.tar"]},{"Config":"%s.json","RepoTags":["other:1.0"],"Layers":[]}]`,
+			id.Hex(), otherID.Hex(),
+		)),
+	}
+	tarball := filepath.Join(tmpDir, "images.tar")
+	writeTarball(t, tarball, files)
+
+	// multiple images - ERROR
+	u, _ := uri.Parse("oci://" + tarball)
+	a, err := Artifact(u)
+	assert.Error(t, err)
+	assert.Nil(t, a)
+
+	u, _ = uri.Parse("oci://" + tarball + ":hello-world:latest")
+	a, err = Artifact(u)
+	assert.NoError(t, err)
+	assert.Equal(t, id.Hex(), a.Hash)
+	assert.Equal(t, "oci://hello-world:latest", a.Name)
+	assert.Nil(t, a.Metadata["version"])
+
+	u, _ = uri.Parse("oci://" + tarball + ":" + otherID.String())
+	a, err = Artifact(u)
+	assert.NoError(t, err)
+	assert.Equal(t, otherID.Hex(), a.Hash)
+	assert.Equal(t, "arm64", a.Metadata["architecture"])
+
+	// tampered layer - ERROR
+	files["abc/layer.tar"] = []byte("tampered")
+	writeTarball(t, tarball, files)
+	u, _ = uri.Parse("oci://" + tarball + ":hello-world:latest")
+	a, err = Artifact(u)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "layer digest mismatch")
+	}
+	assert.Nil(t, a)
+
+	// wrong scheme - SKIP (no error)
+	u, _ = uri.Parse("file://" + tarball)
+	a, err = Artifact(u)
+	assert.NoError(t, err)
+	assert.Nil(t, a)
+
+	// not existing - ERROR
+	u, _ = uri.Parse("oci://" + tmpDir + "/not-existing.tar")
+	a, err = Artifact(u)
+	assert.Error(t, err)
+	assert.Nil(t, a)
+}
@@ -0,0 +1,347 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"runtime"
+	"strings"
+
+	digest "github.com/opencontainers/go-digest"
+)
+
+const (
+	mediaTypeDockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+	mediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+	mediaTypeOCIManifest        = "application/vnd.oci.image.manifest.v1+json"
+	mediaTypeOCIIndex           = "application/vnd.oci.image.index.v1+json"
+)
+
+var acceptedMediaTypes = strings.Join([]string{
+	mediaTypeOCIManifest,
+	mediaTypeOCIIndex,
+	mediaTypeDockerManifest,
+	mediaTypeDockerManifestList,
+}, ", ")
+
+// maxBlobSize limits the size of manifests and configs
+const maxBlobSize = 16 << 20
+
+type descriptor struct {
+	MediaType string        `json:"mediaType"`
+	Digest    digest.Digest `json:"digest"`
+	Platform  *struct {
+		Architecture string `json:"architecture"`
+		OS           string `json:"os"`
+	} `json:"platform,omitempty"`
+}
+
+type manifest struct {
+	SchemaVersion int          `json:"schemaVersion"`
+	MediaType     string       `json:"mediaType"`
+	Config        *descriptor  `json:"config"`
+	Manifests     []descriptor `json:"manifests"`
+}
+
+type config struct {
+	Created      string `json:"created"`
+	Author       string `json:"author"`
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+	RootFS       struct {
+		DiffIDs []string `json:"diff_ids"`
+	} `json:"rootfs"`
+}
+
+type client struct {
+	http     *http.Client
+	baseURL  string
+	user     string
+	password string
+	auth     string
+}
+
+func newClient(host, user, password string) *client {
+	scheme := "https"
+	if isLoopback(host) {
+		scheme = "http"
+	}
+	if host == dockerHub {
+		host = dockerHubRegistry
+	}
+	return &client{
+		http:     &http.Client{},
+		baseURL:  scheme + "://" + host + "/v2/",
+		user:     user,
+		password: password,
+	}
+}
+
+// isLoopback returns true if host refers to the local machine, for which plain HTTP is used.
+func isLoopback(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(strings.Trim(host, "[]"))
+	return ip != nil && ip.IsLoopback()
+}
+
+// image resolves the manifest for r, selecting the current platform for multi-platform images,
+// and returns the image described by its config.
+func (c *client) image(ctx context.Context, r *reference) (*image, error) {
+	m, manifestDigest, err := c.manifest(ctx, r.repo, r.ref())
+	if err != nil {
+		return nil, err
+	}
+
+	if m.Manifests != nil {
+		d, err := selectPlatform(m.Manifests)
+		if err != nil {
+			return nil, err
+		}
+		if m, _, err = c.manifest(ctx, r.repo, d.String()); err != nil {
+			return nil, err
+		}
+	}
+
+	if m.SchemaVersion != 2 || m.Config == nil {
+		return nil, fmt.Errorf("unsupported manifest (schema version %d)", m.SchemaVersion)
+	}
+
+	b, err := c.get(ctx, r.repo, "blobs/"+m.Config.Digest.String(), "")
+	if err != nil {
+		return nil, err
+	}
+	if err := verify(m.Config.Digest, b); err != nil {
+		return nil, err
+	}
+	cfg := config{}
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return nil, fmt.Errorf("invalid image config: %s", err)
+	}
+
+	i := &image{
+		ID:           m.Config.Digest.String(),
+		RepoDigests:  []string{r.name() + "@" + manifestDigest.String()},
+		Created:      cfg.Created,
+		Author:       cfg.Author,
+		Architecture: cfg.Architecture,
+		Os:           cfg.OS,
+		Layers:       cfg.RootFS.DiffIDs,
+	}
+	if r.tag != "" {
+		i.RepoTags = []string{r.name() + ":" + r.tag}
+	}
+	return i, nil
+}
+
+// manifest fetches the manifest for ref, returning it along with its digest.
+func (c *client) manifest(ctx context.Context, repo, ref string) (*manifest, digest.Digest, error) {
+	b, err := c.get(ctx, repo, "manifests/"+ref, acceptedMediaTypes)
+	if err != nil {
+		return nil, "", err
+	}
+	d := digest.SHA256.FromBytes(b)
+	if expected := digest.Digest(ref); expected.Validate() == nil {
+		if err := verify(expected, b); err != nil {
+			return nil, "", err
+		}
+		d = expected
+	}
+	m := &manifest{}
+	if err := json.Unmarshal(b, m); err != nil {
+		return nil, "", fmt.Errorf("invalid manifest: %s", err)
+	}
+	return m, d, nil
+}
+
+// selectPlatform returns the digest of the manifest matching the current platform,
+// falling back to linux on the current architecture.
+func selectPlatform(manifests []descriptor) (digest.Digest, error) {
+	for _, os := range []string{runtime.GOOS, "linux"} {
+		for _, d := range manifests {
+			if d.Platform != nil && d.Platform.OS == os && d.Platform.Architecture == runtime.GOARCH {
+				return d.Digest, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no image found for %s/%s", runtime.GOOS, runtime.GOARCH)
+}
+
+func verify(expected digest.Digest, b []byte) error {
+	if err := expected.Validate(); err != nil {
+		return err
+	}
+	if actual := expected.Algorithm().FromBytes(b); actual != expected {
+		return fmt.Errorf("digest mismatch: expected %s, got %s", expected, actual)
+	}
+	return nil
+}
+
+// get fetches path within repo, authenticating when requested by the registry.
+func (c *client) get(ctx context.Context, repo, path, accept string) ([]byte, error) {
+	res, err := c.do(ctx, c.baseURL+repo+"/"+path, accept)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusUnauthorized && c.auth == "" {
+		challenge := res.Header.Get("WWW-Authenticate")
+		res.Body.Close()
+		if err := c.authenticate(ctx, challenge, repo); err != nil {
+			return nil, err
+		}
+		if res, err = c.do(ctx, c.baseURL+repo+"/"+path, accept); err != nil {
+			return nil, err
+		}
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		return ioutil.ReadAll(io.LimitReader(res.Body, maxBlobSize))
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, fmt.Errorf("access denied to %s", repo)
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("%s not found", path)
+	default:
+		return nil, fmt.Errorf("registry returned %s", res.Status)
+	}
+}
+
+func (c *client) do(ctx context.Context, url, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if c.auth != "" {
+		req.Header.Set("Authorization", c.auth)
+	}
+	return c.http.Do(req)
+}
+
+// authenticate sets the Authorization header value to be used for next requests,
+// according to the given WWW-Authenticate challenge.
+func (c *client) authenticate(ctx context.Context, challenge, repo string) error {
+	scheme, params := parseChallenge(challenge)
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if c.user == "" {
+			return fmt.Errorf("registry requires credentials, please set VCN_REGISTRY_USER and VCN_REGISTRY_PASSWORD")
+		}
+		req, _ := http.NewRequest(http.MethodGet, "", nil)
+		req.SetBasicAuth(c.user, c.password)
+		c.auth = req.Header.Get("Authorization")
+		return nil
+	case "bearer":
+		token, err := c.token(ctx, params, repo)
+		if err != nil {
+			return fmt.Errorf("cannot obtain registry token: %s", err)
+		}
+		c.auth = "Bearer " + token
+		return nil
+	}
+	return fmt.Errorf("unsupported registry authentication: %s", challenge)
+}
+
+// token fetches a bearer token from the realm specified by params.
+func (c *client) token(ctx context.Context, params map[string]string, repo string) (string, error) {
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Host == "" {
+		return "", fmt.Errorf("invalid realm: %s", params["realm"])
+	}
+	q := realm.Query()
+	if service := params["service"]; service != "" {
+		q.Set("service", service)
+	}
+	scope := params["scope"]
+	if scope == "" {
+		scope = "repository:" + repo + ":pull"
+	}
+	q.Set("scope", scope)
+	realm.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if c.user != "" {
+		req.SetBasicAuth(c.user, c.password)
+	}
+	res, err := c.http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s returned %s", realm.Host, res.Status)
+	}
+
+	t := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.NewDecoder(io.LimitReader(res.Body, maxBlobSize)).Decode(&t); err != nil {
+		return "", err
+	}
+	if t.Token != "" {
+		return t.Token, nil
+	}
+	if t.AccessToken != "" {
+		return t.AccessToken, nil
+	}
+	return "", fmt.Errorf("empty token")
+}
+
+// parseChallenge parses a WWW-Authenticate header value like `Bearer realm="...",service="..."`.
+func parseChallenge(s string) (scheme string, params map[string]string) {
+	params = map[string]string{}
+	s = strings.TrimSpace(s)
+	i := strings.Index(s, " ")
+	if i < 0 {
+		return s, params
+	}
+	scheme, s = s[:i], s[i+1:]
+	for s != "" {
+		s = strings.TrimLeft(s, " ,")
+		eq := strings.Index(s, "=")
+		if eq < 0 {
+			break
+		}
+		key := strings.ToLower(strings.TrimSpace(s[:eq]))
+		s = s[eq+1:]
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			end := strings.Index(s[1:], `"`)
+			if end < 0 {
+				value, s = s[1:], ""
+			} else {
+				value, s = s[1:end+1], s[end+2:]
+			}
+		} else if end := strings.Index(s, ","); end >= 0 {
+			value, s = s[:end], s[end:]
+		} else {
+			value, s = s, ""
+		}
+		params[key] = value
+	}
+	return scheme, params
+}
@@ -0,0 +1,191 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/vchain-us/vcn/pkg/api"
+	"github.com/vchain-us/vcn/pkg/extractor"
+	"github.com/vchain-us/vcn/pkg/uri"
+)
+
+// Scheme for images stored in a Docker Registry (HTTP API V2)
+const Scheme = "registry"
+
+const (
+	dockerHub         = "docker.io"
+	dockerHubRegistry = "registry-1.docker.io"
+	defaultTag        = "latest"
+)
+
+type opts struct {
+	user     string
+	password string
+	ctx      context.Context
+}
+
+// WithCredentials returns a functional option to instruct the registry's extractor to authenticate
+// by using the given user and password.
+// If not set, VCN_REGISTRY_USER and VCN_REGISTRY_PASSWORD env vars are used, if any.
+func WithCredentials(user, password string) extractor.Option {
+	return func(o interface{}) error {
+		if o, ok := o.(*opts); ok {
+			o.user = user
+			o.password = password
+		}
+		return nil
+	}
+}
+
+// WithContext returns a functional option to bound all the registry's requests by ctx,
+// for cancellation and timeouts. If not set, context.Background() is used.
+func WithContext(ctx context.Context) extractor.Option {
+	return func(o interface{}) error {
+		if o, ok := o.(*opts); ok {
+			o.ctx = ctx
+		}
+		return nil
+	}
+}
+
+// Artifact returns an image *api.Artifact from a given u.
+//
+// The u must be in the form "registry://<host>/<repository>[:<tag>|@<digest>]".
+// Only the image manifest and config are fetched, no layer is downloaded.
+// The resulting hash is the image ID, the same returned by `docker inspect` once the image is pulled.
+func Artifact(u *uri.URI, options ...extractor.Option) (*api.Artifact, error) {
+
+	if u.Scheme != Scheme {
+		return nil, nil
+	}
+
+	opts := &opts{
+		user:     os.Getenv("VCN_REGISTRY_USER"),
+		password: os.Getenv("VCN_REGISTRY_PASSWORD"),
+		ctx:      context.Background(),
+	}
+	if err := extractor.Options(options).Apply(opts); err != nil {
+		return nil, err
+	}
+
+	r, err := parseReference(strings.TrimPrefix(u.Opaque, "//"))
+	if err != nil {
+		return nil, err
+	}
+
+	c := newClient(r.host, opts.user, opts.password)
+	i, err := c.image(opts.ctx, r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s image: %s", r, err)
+	}
+
+	m := api.Metadata{
+		"architecture": i.Architecture,
+		"platform":     i.Os,
+	}
+
+	if version := r.inferVer(); version != "" {
+		m["version"] = version
+	}
+
+	m[Scheme] = i
+	return &api.Artifact{
+		Kind:     Scheme,
+		Name:     Scheme + "://" + r.String(),
+		Hash:     i.hash(),
+		Metadata: m,
+	}, nil
+}
+
+type reference struct {
+	host   string
+	repo   string
+	tag    string
+	digest string
+}
+
+// parseReference parses s as "<host>/<repository>[:<tag>|@<digest>]".
+// When the host is omitted, Docker Hub is assumed.
+func parseReference(s string) (*reference, error) {
+	r := &reference{}
+
+	if i := strings.Index(s, "@"); i >= 0 {
+		s, r.digest = s[:i], s[i+1:]
+	}
+	if i := strings.LastIndex(s, ":"); i > strings.LastIndex(s, "/") {
+		s, r.tag = s[:i], s[i+1:]
+	}
+
+	parts := strings.SplitN(s, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		r.host, r.repo = parts[0], parts[1]
+	} else {
+		r.host, r.repo = dockerHub, s
+	}
+	if r.host == dockerHub && !strings.Contains(r.repo, "/") {
+		r.repo = "library/" + r.repo
+	}
+
+	if r.repo == "" || strings.HasPrefix(r.repo, "/") || strings.HasSuffix(r.repo, "/") {
+		return nil, fmt.Errorf("invalid image reference: %s", s)
+	}
+	if r.tag == "" && r.digest == "" {
+		r.tag = defaultTag
+	}
+	return r, nil
+}
+
+// ref returns the digest, if any, otherwise the tag.
+func (r reference) ref() string {
+	if r.digest != "" {
+		return r.digest
+	}
+	return r.tag
+}
+
+func (r reference) name() string {
+	return r.host + "/" + r.repo
+}
+
+func (r reference) String() string {
+	s := r.name()
+	if r.tag != "" {
+		s += ":" + r.tag
+	}
+	if r.digest != "" {
+		s += "@" + r.digest
+	}
+	return s
+}
+
+func (r reference) inferVer() string {
+	if r.tag != defaultTag {
+		return r.tag
+	}
+	return ""
+}
+
+type image struct {
+	ID           string   `json:"Id"`
+	RepoTags     []string `json:"RepoTags"`
+	RepoDigests  []string `json:"RepoDigests"`
+	Created      string   `json:"Created"`
+	Author       string   `json:"Author"`
+	Architecture string   `json:"Architecture"`
+	Os           string   `json:"Os"`
+	Layers       []string `json:"Layers"`
+}
+
+func (i image) hash() string {
+	return strings.TrimPrefix(i.ID, "sha256:")
+}
@@ -0,0 +1,155 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vchain-us/vcn/internal/sim"
+	"github.com/vchain-us/vcn/pkg/uri"
+)
+
+const testConfig = `{"architecture":"amd64","os":"linux","created":"2019-07-11T22:20:52Z","rootfs":{"type":"layers","diff_ids":["sha256:1bfeebd65323b8ddf5bd6a51cc7097b72788bc982e9ab3280d53d3c613adffa7"]}}`
+
+func TestRegistry(t *testing.T) {
+	r := sim.NewRegistry()
+	defer r.Close()
+	id := r.PushImage("vchain/alpine", "3.10", []byte(testConfig))
+
+	u, _ := uri.Parse("registry://" + r.Host() + "/vchain/alpine:3.10")
+	a, err := Artifact(u)
+	assert.NoError(t, err)
+	assert.NotNil(t, a)
+	assert.Equal(t, Scheme, a.Kind)
+	assert.Equal(t, "registry://"+r.Host()+"/vchain/alpine:3.10", a.Name)
+	assert.Equal(t, id.Hex(), a.Hash)
+	assert.Equal(t, "amd64", a.Metadata["architecture"])
+	assert.Equal(t, "linux", a.Metadata["platform"])
+	assert.Equal(t, "3.10", a.Metadata["version"])
+	i := a.Metadata[Scheme].(*image)
+	assert.Equal(t, []string{r.Host() + "/vchain/alpine:3.10"}, i.RepoTags)
+	assert.Len(t, i.RepoDigests, 1)
+	assert.Len(t, i.Layers, 1)
+
+	// by digest
+	u, _ = uri.Parse("registry://" + i.RepoDigests[0])
+	a, err = Artifact(u)
+	assert.NoError(t, err)
+	assert.Equal(t, id.Hex(), a.Hash)
+
+	// not found
+	u, _ = uri.Parse("registry://" + r.Host() + "/vchain/alpine:missing")
+	a, err = Artifact(u)
+	assert.Error(t, err)
+	assert.Nil(t, a)
+
+	// not a registry URI
+	u, _ = uri.Parse("docker://alpine")
+	a, err = Artifact(u)
+	assert.NoError(t, err)
+	assert.Nil(t, a)
+}
+
+func TestRegistryIndex(t *testing.T) {
+	r := sim.NewRegistry()
+	defer r.Close()
+
+	current := fmt.Sprintf(`{"architecture":"%s","os":"linux"}`, runtime.GOARCH)
+	r.PushIndex("multi", "latest", []byte(`{"architecture":"s390x","os":"linux"}`), []byte(current))
+
+	u, _ := uri.Parse("registry://" + r.Host() + "/multi")
+	a, err := Artifact(u)
+	assert.NoError(t, err)
+	assert.Equal(t, runtime.GOARCH, a.Metadata["architecture"])
+	assert.NotContains(t, a.Metadata, "version")
+}
+
+func TestRegistryAuth(t *testing.T) {
+	for _, bearer := range []bool{false, true} {
+		r := sim.NewRegistry()
+		r.SetCredentials("user", "secret", bearer)
+		id := r.PushImage("private", "1.0", []byte(testConfig))
+		u, _ := uri.Parse("registry://" + r.Host() + "/private:1.0")
+
+		_, err := Artifact(u)
+		assert.Error(t, err, "bearer: %t", bearer)
+
+		_, err = Artifact(u, WithCredentials("user", "wrong"))
+		assert.Error(t, err, "bearer: %t", bearer)
+
+		a, err := Artifact(u, WithCredentials("user", "secret"))
+		assert.NoError(t, err, "bearer: %t", bearer)
+		if assert.NotNil(t, a) {
+			assert.Equal(t, id.Hex(), a.Hash)
+		}
+		r.Close()
+	}
+}
+
+func TestRegistryContext(t *testing.T) {
+	// a registry that never answers
+	done := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-done:
+		case <-r.Context().Done():
+		}
+	}))
+	defer ts.Close()
+	defer close(done)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	u, _ := uri.Parse("registry://" + strings.TrimPrefix(ts.URL, "http://") + "/vchain/alpine:3.10")
+	start := time.Now()
+	a, err := Artifact(u, WithContext(ctx))
+	assert.Error(t, err)
+	assert.Nil(t, a)
+	assert.True(t, time.Since(start) < 5*time.Second)
+}
+
+func TestParseReference(t *testing.T) {
+	for s, expected := range map[string]reference{
+		"alpine":                       {host: "docker.io", repo: "library/alpine", tag: "latest"},
+		"vchain/vcn:0.7":               {host: "docker.io", repo: "vchain/vcn", tag: "0.7"},
+		"localhost:5000/app":           {host: "localhost:5000", repo: "app", tag: "latest"},
+		"quay.io/org/app@sha256:abcd":  {host: "quay.io", repo: "org/app", digest: "sha256:abcd"},
+		"quay.io/org/app:1@sha256:abc": {host: "quay.io", repo: "org/app", tag: "1", digest: "sha256:abc"},
+	} {
+		r, err := parseReference(s)
+		assert.NoError(t, err, s)
+		assert.Equal(t, expected, *r, s)
+	}
+
+	_, err := parseReference("localhost:5000/")
+	assert.Error(t, err)
+}
+
+func TestParseChallenge(t *testing.T) {
+	scheme, params := parseChallenge(`Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:a/b:pull,push"`)
+	assert.Equal(t, "Bearer", scheme)
+	assert.Equal(t, map[string]string{
+		"realm":   "https://auth.docker.io/token",
+		"service": "registry.docker.io",
+		"scope":   "repository:a/b:pull,push",
+	}, params)
+
+	assert.True(t, isLoopback("127.0.0.1:5000"))
+	assert.True(t, isLoopback("localhost"))
+	assert.False(t, isLoopback("quay.io"))
+}
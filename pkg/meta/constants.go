@@ -12,6 +12,8 @@ import (
 	"fmt"
 	"log"
 	"runtime"
+	"strconv"
+	"strings"
 
 	"github.com/fatih/color"
 )
@@ -94,6 +96,29 @@ func (l Level) String() string {
 	}
 }
 
+// ParseLevel returns the Level matching s, that can be either its value (e.g. "3")
+// or its name (e.g. "ID_VERIFIED"), case insensitive.
+func ParseLevel(s string) (Level, error) {
+	for _, l := range []Level{
+		LevelDisabled,
+		LevelUnknown,
+		LevelEmailVerified,
+		LevelSocialVerified,
+		LevelIDVerified,
+		LevelLocationVerified,
+		LevelVchain,
+	} {
+		name := l.String()
+		if i := strings.Index(name, " - "); i >= 0 {
+			name = name[i+3:]
+		}
+		if s == strconv.FormatInt(int64(l), 10) || strings.EqualFold(s, name) {
+			return l, nil
+		}
+	}
+	return LevelUnknown, fmt.Errorf("invalid level: %s", s)
+}
+
 // String returns the name of the given status as string
 func (s Status) String() string {
 	switch s {
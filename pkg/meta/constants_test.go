@@ -0,0 +1,59 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package meta
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLevel(t *testing.T) {
+	testCases := map[string]struct {
+		level Level
+		err   bool
+	}{
+		// values
+		"-1": {level: LevelDisabled},
+		"0":  {level: LevelUnknown},
+		"1":  {level: LevelEmailVerified},
+		"3":  {level: LevelIDVerified},
+		"99": {level: LevelVchain},
+
+		// names
+		"DISABLED":          {level: LevelDisabled},
+		"EMAIL_VERIFIED":    {level: LevelEmailVerified},
+		"SOCIAL_VERIFIED":   {level: LevelSocialVerified},
+		"ID_VERIFIED":       {level: LevelIDVerified},
+		"LOCATION_VERIFIED": {level: LevelLocationVerified},
+		"VCHAIN":            {level: LevelVchain},
+
+		// mixed case
+		"id_verified":    {level: LevelIDVerified},
+		"Email_Verified": {level: LevelEmailVerified},
+
+		// invalid
+		"":                {err: true},
+		"5":               {err: true},
+		"1.0":             {err: true},
+		" 3":              {err: true},
+		"ID":              {err: true},
+		"3 - ID_VERIFIED": {err: true},
+	}
+
+	for s, tc := range testCases {
+		l, err := ParseLevel(s)
+		if tc.err {
+			assert.Error(t, err, s)
+			continue
+		}
+		assert.NoError(t, err, s)
+		assert.Equal(t, tc.level, l, s)
+	}
+}
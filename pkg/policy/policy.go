@@ -0,0 +1,298 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package policy
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/vchain-us/vcn/pkg/api"
+	"github.com/vchain-us/vcn/pkg/meta"
+)
+
+// Rule names, as reported by Result.
+const (
+	RuleStatus            = "status"
+	RuleMinLevel          = "minLevel"
+	RuleAllow             = "allow"
+	RuleMaxAge            = "maxAge"
+	RuleRequiredMetadata  = "requiredMetadata"
+	RuleRejectUntrustedBy = "rejectUntrustedBy"
+)
+
+var signerIDRegExp = regexp.MustCompile("^0x[0-9a-f]{40}$")
+
+// Allow is the set of signers and organisations that are allowed to notarize assets of the given kinds.
+type Allow struct {
+	// Kinds the entry applies to, any kind if empty.
+	Kinds []string `yaml:"kinds"`
+
+	// Signers is the list of allowed SignerIDs.
+	Signers []string `yaml:"signers"`
+
+	// Orgs is the list of allowed organisations' IDs, each one allowing all its members.
+	Orgs []string `yaml:"orgs"`
+
+	members []string
+}
+
+// Policy is a set of rules that an authentication must satisfy.
+// The TRUSTED status is always required, other rules apply only when set.
+//
+// Policy files are YAML documents, for example:
+//
+//	minLevel: 1
+//	maxAge: 720h
+//	allow:
+//	  - kinds: [docker, podman]
+//	    orgs: [vchain.us]
+//	  - signers: [0x...]
+//	requiredMetadata: [version]
+//	rejectUntrustedBy: [0x...]
+type Policy struct {
+	// MinLevel is the minimum level of the signer (see meta.Level).
+	MinLevel meta.Level `yaml:"minLevel"`
+
+	// MaxAge is the maximum age of the notarization, 0 means no limit.
+	MaxAge time.Duration `yaml:"maxAge"`
+
+	// Allow lists who is allowed to notarize assets, per kind.
+	// The first entry matching the asset kind applies.
+	Allow []Allow `yaml:"allow"`
+
+	// RequiredMetadata lists the metadata attributes the notarized asset must have.
+	RequiredMetadata []string `yaml:"requiredMetadata"`
+
+	// RejectUntrustedBy lists the SignerIDs whose UNTRUSTED entries, if any, make the asset rejected.
+	RejectUntrustedBy []string `yaml:"rejectUntrustedBy"`
+}
+
+// Subject is the outcome of an authentication the policy is evaluated against.
+type Subject struct {
+	// Kind is the asset kind.
+	Kind string
+
+	// Metadata are the notarized asset's metadata.
+	Metadata api.Metadata
+
+	// Verification is the authentication's blockchain entry.
+	Verification *api.BlockchainVerification
+
+	// History is the list of all blockchain entries for the asset (see api.BlockChainInspect).
+	History []api.BlockchainVerification
+
+	// Now is the time the notarization age is computed at.
+	Now time.Time
+}
+
+// RuleResult is the result of a single rule.
+type RuleResult struct {
+	Rule   string `json:"rule" yaml:"rule"`
+	Passed bool   `json:"passed" yaml:"passed"`
+	Reason string `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+// Result is the result of a policy evaluation.
+type Result struct {
+	Passed bool         `json:"passed" yaml:"passed"`
+	Rules  []RuleResult `json:"rules" yaml:"rules"`
+}
+
+// Failed returns the results of the rules that did not pass.
+func (r *Result) Failed() []RuleResult {
+	failed := []RuleResult{}
+	if r != nil {
+		for _, rr := range r.Rules {
+			if !rr.Passed {
+				failed = append(failed, rr)
+			}
+		}
+	}
+	return failed
+}
+
+// Load reads the policy file named by filename.
+func Load(filename string) (*Policy, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	p := Policy{}
+	if err := yaml.UnmarshalStrict(data, &p); err != nil {
+		return nil, fmt.Errorf("invalid policy %s: %s", filename, err)
+	}
+	if err := p.validate(); err != nil {
+		return nil, fmt.Errorf("invalid policy %s: %s", filename, err)
+	}
+	return &p, nil
+}
+
+func normalizeSignerIDs(ids []string) ([]string, error) {
+	for i, id := range ids {
+		id = strings.ToLower(id)
+		if !strings.HasPrefix(id, "0x") {
+			id = "0x" + id
+		}
+		if !signerIDRegExp.MatchString(id) {
+			return nil, fmt.Errorf("invalid SignerID: %s", ids[i])
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+func (p *Policy) validate() (err error) {
+	if p.MaxAge < 0 {
+		return fmt.Errorf("maxAge cannot be negative")
+	}
+	for i, a := range p.Allow {
+		if len(a.Signers) == 0 && len(a.Orgs) == 0 {
+			return fmt.Errorf("allow entry %d has neither signers nor orgs", i)
+		}
+		if p.Allow[i].Signers, err = normalizeSignerIDs(a.Signers); err != nil {
+			return err
+		}
+	}
+	p.RejectUntrustedBy, err = normalizeSignerIDs(p.RejectUntrustedBy)
+	return err
+}
+
+// Resolve fetches the members of the organisations allowed by p.
+// It must be called before SignerIDs and Evaluate, if p allows any organisation.
+func (p *Policy) Resolve(ctx context.Context) error {
+	for i, a := range p.Allow {
+		members := append([]string{}, a.Signers...)
+		for _, org := range a.Orgs {
+			bo, err := api.GetBlockChainOrganisationContext(ctx, org)
+			if err != nil {
+				return fmt.Errorf("cannot resolve organisation %s: %s", org, err)
+			}
+			members = append(members, bo.MembersIDs()...)
+		}
+		p.Allow[i].members = members
+	}
+	return nil
+}
+
+// allow returns the entry applying to kind, if any.
+func (p *Policy) allow(kind string) *Allow {
+	for i, a := range p.Allow {
+		if len(a.Kinds) == 0 {
+			return &p.Allow[i]
+		}
+		for _, k := range a.Kinds {
+			if k == kind {
+				return &p.Allow[i]
+			}
+		}
+	}
+	return nil
+}
+
+// SignerIDs returns the SignerIDs allowed to notarize assets of the given kind,
+// or nil if p does not restrict signers.
+func (p *Policy) SignerIDs(kind string) []string {
+	if len(p.Allow) == 0 {
+		return nil
+	}
+	if a := p.allow(kind); a != nil {
+		if a.members != nil {
+			return a.members
+		}
+		return a.Signers
+	}
+	return []string{}
+}
+
+// NeedsHistory returns true if Evaluate needs the subject's History.
+func (p *Policy) NeedsHistory() bool {
+	return len(p.RejectUntrustedBy) > 0
+}
+
+func contains(ids []string, id string) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Evaluate returns the result of each rule of p against s.
+func (p *Policy) Evaluate(s Subject) *Result {
+	v := s.Verification
+	if v == nil {
+		v = &api.BlockchainVerification{Status: meta.StatusUnknown}
+	}
+
+	r := &Result{Passed: true}
+	add := func(rule string, passed bool, format string, a ...interface{}) {
+		rr := RuleResult{Rule: rule, Passed: passed}
+		if !passed {
+			rr.Reason = fmt.Sprintf(format, a...)
+			r.Passed = false
+		}
+		r.Rules = append(r.Rules, rr)
+	}
+
+	add(RuleStatus, v.Trusted(), "status is %s", v.Status)
+
+	if p.MinLevel > meta.LevelUnknown {
+		add(RuleMinLevel, v.Level >= p.MinLevel, "level %d is lower than %d", v.Level, p.MinLevel)
+	}
+
+	if len(p.Allow) > 0 {
+		signerID := v.SignerID()
+		switch ids := p.SignerIDs(s.Kind); true {
+		case len(ids) == 0:
+			add(RuleAllow, false, "no signer is allowed for kind %q", s.Kind)
+		case signerID == "":
+			add(RuleAllow, false, "no signer found")
+		default:
+			add(RuleAllow, contains(ids, signerID), "signer %s is not allowed for kind %q", signerID, s.Kind)
+		}
+	}
+
+	if p.MaxAge > 0 {
+		if v.Timestamp.IsZero() {
+			add(RuleMaxAge, false, "notarization date is unknown")
+		} else {
+			age := s.Now.Sub(v.Timestamp)
+			add(RuleMaxAge, age <= p.MaxAge, "notarized %s ago, more than %s", age.Round(time.Second), p.MaxAge)
+		}
+	}
+
+	if len(p.RequiredMetadata) > 0 {
+		missing := []string{}
+		for _, k := range p.RequiredMetadata {
+			if _, ok := s.Metadata[k]; !ok {
+				missing = append(missing, k)
+			}
+		}
+		add(RuleRequiredMetadata, len(missing) == 0, "missing metadata: %s", strings.Join(missing, ", "))
+	}
+
+	if len(p.RejectUntrustedBy) > 0 {
+		untrustedBy := []string{}
+		for _, h := range s.History {
+			if id := h.SignerID(); h.Status == meta.StatusUntrusted && contains(p.RejectUntrustedBy, id) && !contains(untrustedBy, id) {
+				untrustedBy = append(untrustedBy, id)
+			}
+		}
+		add(RuleRejectUntrustedBy, len(untrustedBy) == 0, "untrusted by %s", strings.Join(untrustedBy, ", "))
+	}
+
+	return r
+}
@@ -0,0 +1,159 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package policy
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vchain-us/vcn/pkg/api"
+	"github.com/vchain-us/vcn/pkg/meta"
+)
+
+const (
+	alice = "0x1111111111111111111111111111111111111111"
+	bob   = "0x2222222222222222222222222222222222222222"
+)
+
+func loadPolicy(t *testing.T, data string) (*Policy, error) {
+	tdir, err := ioutil.TempDir("", "vcn-policy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tdir)
+
+	filename := filepath.Join(tdir, "policy.yaml")
+	if err := ioutil.WriteFile(filename, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return Load(filename)
+}
+
+func TestLoad(t *testing.T) {
+	p, err := loadPolicy(t, `
+minLevel: 2
+maxAge: 720h
+allow:
+  - kinds: [docker]
+    orgs: [vchain.us]
+  - signers: [1111111111111111111111111111111111111111]
+requiredMetadata: [version]
+rejectUntrustedBy: [`+bob+`]
+`)
+	assert.NoError(t, err)
+	assert.Equal(t, meta.LevelSocialVerified, p.MinLevel)
+	assert.Equal(t, 720*time.Hour, p.MaxAge)
+	assert.Equal(t, []string{"vchain.us"}, p.Allow[0].Orgs)
+	assert.Equal(t, []string{alice}, p.Allow[1].Signers)
+	assert.Equal(t, []string{"version"}, p.RequiredMetadata)
+	assert.Equal(t, []string{bob}, p.RejectUntrustedBy)
+	assert.True(t, p.NeedsHistory())
+
+	_, err = loadPolicy(t, "minLevel: 1\nunknownRule: true\n")
+	assert.Error(t, err)
+
+	_, err = loadPolicy(t, "allow:\n  - kinds: [file]\n")
+	assert.Error(t, err)
+
+	_, err = loadPolicy(t, "rejectUntrustedBy: [0x1234]\n")
+	assert.Error(t, err)
+
+	_, err = loadPolicy(t, "rejectUntrustedBy: [0xzz11111111111111111111111111111111111111]\n")
+	assert.Error(t, err)
+}
+
+func TestSignerIDs(t *testing.T) {
+	p := Policy{}
+	assert.Nil(t, p.SignerIDs("file"))
+
+	p.Allow = []Allow{
+		{Kinds: []string{"docker"}, Signers: []string{alice}},
+		{Kinds: []string{"file", "dir"}, Signers: []string{bob}},
+	}
+	assert.Equal(t, []string{alice}, p.SignerIDs("docker"))
+	assert.Equal(t, []string{bob}, p.SignerIDs("dir"))
+	assert.Empty(t, p.SignerIDs("git"))
+	assert.NotNil(t, p.SignerIDs("git"))
+}
+
+func TestEvaluate(t *testing.T) {
+	now := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+	v := &api.BlockchainVerification{
+		Owner:     common.HexToAddress(alice),
+		Level:     meta.LevelEmailVerified,
+		Status:    meta.StatusTrusted,
+		Timestamp: now.Add(-48 * time.Hour),
+	}
+	s := Subject{
+		Kind:         "file",
+		Metadata:     api.Metadata{"version": "1.0"},
+		Verification: v,
+		History: []api.BlockchainVerification{
+			{Owner: common.HexToAddress(bob), Status: meta.StatusTrusted},
+			*v,
+		},
+		Now: now,
+	}
+
+	// status only
+	p := Policy{}
+	r := p.Evaluate(s)
+	assert.True(t, r.Passed)
+	assert.Equal(t, []RuleResult{{Rule: RuleStatus, Passed: true}}, r.Rules)
+
+	// all rules passing
+	p = Policy{
+		MinLevel:          meta.LevelEmailVerified,
+		MaxAge:            72 * time.Hour,
+		Allow:             []Allow{{Signers: []string{alice}}},
+		RequiredMetadata:  []string{"version"},
+		RejectUntrustedBy: []string{bob},
+	}
+	r = p.Evaluate(s)
+	assert.True(t, r.Passed)
+	assert.Len(t, r.Rules, 6)
+	assert.Empty(t, r.Failed())
+
+	// all rules failing
+	p = Policy{
+		MinLevel:          meta.LevelIDVerified,
+		MaxAge:            24 * time.Hour,
+		Allow:             []Allow{{Signers: []string{bob}}},
+		RequiredMetadata:  []string{"version", "platform"},
+		RejectUntrustedBy: []string{bob},
+	}
+	s.History = append(s.History, api.BlockchainVerification{Owner: common.HexToAddress(bob), Status: meta.StatusUntrusted})
+	s.Verification = &api.BlockchainVerification{
+		Owner:     v.Owner,
+		Level:     v.Level,
+		Status:    meta.StatusUnsupported,
+		Timestamp: v.Timestamp,
+	}
+	r = p.Evaluate(s)
+	assert.False(t, r.Passed)
+	assert.Equal(t, []RuleResult{
+		{Rule: RuleStatus, Reason: "status is UNSUPPORTED"},
+		{Rule: RuleMinLevel, Reason: "level 1 is lower than 3"},
+		{Rule: RuleAllow, Reason: `signer ` + alice + ` is not allowed for kind "file"`},
+		{Rule: RuleMaxAge, Reason: "notarized 48h0m0s ago, more than 24h0m0s"},
+		{Rule: RuleRequiredMetadata, Reason: "missing metadata: platform"},
+		{Rule: RuleRejectUntrustedBy, Reason: "untrusted by " + bob},
+	}, r.Failed())
+
+	// unknown asset
+	r = p.Evaluate(Subject{Kind: "file", Now: now})
+	assert.False(t, r.Passed)
+	assert.Len(t, r.Failed(), 5)
+}
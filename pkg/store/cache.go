@@ -0,0 +1,156 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const cacheDirname = "cache"
+
+const cacheExt = ".json"
+
+// CacheEntry is a cached value for a hash and a set of signers.
+type CacheEntry struct {
+	Key       string          `json:"key"`
+	Hash      string          `json:"hash"`
+	Signers   []string        `json:"signers,omitempty"`
+	Value     json.RawMessage `json:"value"`
+	CreatedAt time.Time       `json:"createdAt"`
+	ExpiresAt time.Time       `json:"expiresAt"`
+}
+
+// Expired returns true if e must not be used anymore.
+func (e CacheEntry) Expired() bool {
+	return !time.Now().Before(e.ExpiresAt)
+}
+
+func cacheDir() string {
+	return filepath.Join(dir, cacheDirname)
+}
+
+// CacheKey returns the key for the given contract, scope, hash and signers,
+// where contract identifies the ledger the verification comes from.
+// The order of signers does not matter.
+func CacheKey(contract string, scope string, hash string, signers []string) string {
+	s := make([]string, len(signers))
+	for i, signer := range signers {
+		s[i] = strings.ToLower(signer)
+	}
+	sort.Strings(s)
+	sum := sha256.Sum256([]byte(strings.ToLower(contract) + "\n" + scope + "\n" + hash + "\n" + strings.Join(s, "\n")))
+	return fmt.Sprintf("%x", sum)
+}
+
+func readCacheEntry(filename string) (*CacheEntry, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	e := &CacheEntry{}
+	if err := json.Unmarshal(b, e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// ReadCache returns the cached entry for key, if any and not expired, otherwise nil.
+func ReadCache(key string) *CacheEntry {
+	e, err := readCacheEntry(filepath.Join(cacheDir(), key+cacheExt))
+	if err != nil || e.Expired() {
+		return nil
+	}
+	return e
+}
+
+// WriteCache stores value for key, valid for the given ttl.
+func WriteCache(key string, hash string, signers []string, value json.RawMessage, ttl time.Duration) error {
+	now := time.Now()
+	b, err := json.Marshal(CacheEntry{
+		Key:       key,
+		Hash:      hash,
+		Signers:   signers,
+		Value:     value,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	})
+	if err != nil {
+		return err
+	}
+	return writeFile(filepath.Join(cacheDir(), key+cacheExt), b)
+}
+
+// CacheEntries returns all cache entries, expired ones included, sorted by creation time.
+func CacheEntries() ([]CacheEntry, error) {
+	files, err := filepath.Glob(filepath.Join(cacheDir(), "*"+cacheExt))
+	if err != nil {
+		return nil, err
+	}
+	entries := []CacheEntry{}
+	for _, f := range files {
+		e, err := readCacheEntry(f)
+		if err != nil {
+			continue // ignore bad entries
+		}
+		entries = append(entries, *e)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+	})
+	return entries, nil
+}
+
+// removeCache removes all entries matching fn, and returns the number of removed entries.
+// Unreadable entries are always removed.
+func removeCache(fn func(e CacheEntry) bool) (int, error) {
+	files, err := filepath.Glob(filepath.Join(cacheDir(), "*"+cacheExt))
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, f := range files {
+		if e, err := readCacheEntry(f); err == nil && !fn(*e) {
+			continue
+		}
+		if err := os.Remove(f); err != nil && !os.IsNotExist(err) {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// PruneCache removes expired entries, and returns the number of removed entries.
+func PruneCache() (int, error) {
+	return removeCache(func(e CacheEntry) bool {
+		return e.Expired()
+	})
+}
+
+// ClearCache removes all entries, and returns the number of removed entries.
+func ClearCache() (int, error) {
+	return removeCache(func(e CacheEntry) bool {
+		return true
+	})
+}
+
+// InvalidateCache removes all entries for hash, and returns the number of removed entries.
+func InvalidateCache(hash string) (int, error) {
+	return removeCache(func(e CacheEntry) bool {
+		return e.Hash == hash
+	})
+}
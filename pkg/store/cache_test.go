@@ -0,0 +1,70 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package store
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache(t *testing.T) {
+	tdir, err := ioutil.TempDir("", "vcn-test-store-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tdir)
+	SetDir(tdir)
+
+	assert.Equal(t, CacheKey("0xc", "any", "h", []string{"0xA", "0xb"}), CacheKey("0xC", "any", "h", []string{"0xB", "0xa"}))
+	assert.NotEqual(t, CacheKey("0xc", "any", "h", nil), CacheKey("0xc", "signers", "h", nil))
+	assert.NotEqual(t, CacheKey("0xc", "any", "h", nil), CacheKey("0xd", "any", "h", nil))
+
+	k1 := CacheKey("0xc", "any", "h1", nil)
+	k2 := CacheKey("0xc", "any", "h2", nil)
+	k3 := CacheKey("0xc", "signers", "h1", []string{"0x0"})
+
+	assert.Nil(t, ReadCache(k1))
+
+	assert.NoError(t, WriteCache(k1, "h1", nil, json.RawMessage(`{"status":0}`), time.Minute))
+	assert.NoError(t, WriteCache(k2, "h2", nil, json.RawMessage(`{"status":1}`), -time.Second))
+	assert.NoError(t, WriteCache(k3, "h1", []string{"0x0"}, json.RawMessage(`{"status":0}`), time.Minute))
+
+	e := ReadCache(k1)
+	if assert.NotNil(t, e) {
+		assert.Equal(t, "h1", e.Hash)
+		assert.JSONEq(t, `{"status":0}`, string(e.Value))
+	}
+	assert.Nil(t, ReadCache(k2)) // expired
+
+	entries, err := CacheEntries()
+	assert.NoError(t, err)
+	assert.Len(t, entries, 3)
+
+	n, err := PruneCache()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	n, err = InvalidateCache("h1")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	assert.NoError(t, WriteCache(k1, "h1", nil, json.RawMessage(`{}`), time.Minute))
+	n, err = ClearCache()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	entries, err = CacheEntries()
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
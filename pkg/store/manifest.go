@@ -0,0 +1,28 @@
+/*
+ * Copyright (c) 2018-2019 vChain, Inc. All Rights Reserved.
+ * This software is released under GPL3.
+ * The full license information can be found under:
+ * https://www.gnu.org/licenses/gpl-3.0.en.html
+ *
+ */
+
+package store
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+const manifestsDirname = "manifests"
+
+const manifestExt = ".json"
+
+// ManifestFile returns the filename of the locally stored manifest notarized by hash.
+func ManifestFile(hash string) string {
+	return filepath.Join(dir, manifestsDirname, strings.ToLower(hash)+manifestExt)
+}
+
+// WriteManifest locally stores data as the manifest notarized by hash.
+func WriteManifest(hash string, data []byte) error {
+	return writeFile(ManifestFile(hash), data)
+}
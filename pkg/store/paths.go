@@ -9,6 +9,7 @@
 package store
 
 import (
+	"io/ioutil"
 	"os"
 	"path/filepath"
 
@@ -28,6 +29,32 @@ func ensureDir(path string) error {
 	return nil
 }
 
+// writeFile writes data to filename, creating its directory if needed.
+// Data is written to a temp file first, so concurrent readers never see partial content.
+func writeFile(filename string, data []byte) error {
+	if err := ensureDir(filepath.Dir(filename)); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(filename), filepath.Base(filename))
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), FilePerm); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
+
 func defaultConfigFilepath() string {
 	return filepath.Join(dir, configFilename)
 }